@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// levelPrefixRe matches a level prefix such as "[WARN] ", "WARNING: " or "error: " at the start of a line.
+var levelPrefixRe = regexp.MustCompile(`(?i)^\s*(?:\[(debug|info|warn|warning|error|err)\]:?|(debug|info|warn|warning|error|err):)\s*`)
+
+// WriterOptions configures the io.Writer and *log.Logger bridges.
+type WriterOptions struct {
+	Level      string // Level is the level of records: "debug", "info" (default), "warn" or "error"
+	ParseLevel bool   // ParseLevel takes the level from a prefix such as "[WARN]" or "error:" and strips it
+}
+
+// writer turns each line written to it into a record of a logger.
+type writer struct {
+	logger     *slog.Logger
+	level      slog.Level
+	parseLevel bool
+
+	mu  sync.Mutex
+	buf []byte // buf holds an incomplete trailing line
+}
+
+// NewWriter returns an io.Writer that logs each line written to it as a record of l,
+// for libraries that accept an io.Writer for their logs. Lines are split on "\n";
+// an incomplete trailing line is held until the line is completed or the writer is flushed
+// through the Flusher interface.
+// The source of records is the caller of the log package, or of Write for other consumers.
+func NewWriter(l *slog.Logger, opts WriterOptions) io.Writer {
+	return &writer{
+		logger:     l,
+		level:      ParseLevel(opts.Level),
+		parseLevel: opts.ParseLevel,
+	}
+}
+
+// NewStdLogger returns a *log.Logger that logs each line as a record of l,
+// for libraries that take a *log.Logger.
+func NewStdLogger(l *slog.Logger, opts WriterOptions) *log.Logger {
+	return log.New(NewWriter(l, opts), "", 0)
+}
+
+// Write implements io.Writer. It never fails.
+func (w *writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pc := callerPC()
+
+	data := p
+	if len(w.buf) > 0 {
+		data = append(w.buf, p...)
+		w.buf = nil
+	}
+
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.log(pc, string(data[:i]))
+		data = data[i+1:]
+	}
+
+	if len(data) > 0 {
+		w.buf = append([]byte(nil), data...)
+	}
+
+	return len(p), nil
+}
+
+// Flush logs an incomplete trailing line held by the writer. It implements Flusher.
+func (w *writer) Flush(_ context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.log(0, string(w.buf))
+		w.buf = nil
+	}
+
+	return nil
+}
+
+// log emits a single line as a record.
+func (w *writer) log(pc uintptr, line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	level := w.level
+	if w.parseLevel {
+		if m := levelPrefixRe.FindStringSubmatch(line); m != nil {
+			level = ParseLevel(normalizeLevel(m[1] + m[2]))
+			line = line[len(m[0]):]
+		}
+	}
+
+	ctx := context.Background()
+	if !w.logger.Enabled(ctx, level) {
+		return
+	}
+
+	r := slog.NewRecord(time.Now(), level, line, pc)
+	_ = w.logger.Handler().Handle(ctx, r)
+}
+
+// normalizeLevel maps level prefix spellings to the names understood by ParseLevel.
+func normalizeLevel(s string) string {
+	switch s = strings.ToLower(s); s {
+	case "warning":
+		return "warn"
+	case "err":
+		return "error"
+	default:
+		return s
+	}
+}
+
+// callerPC returns the program counter of the first caller outside of the log, fmt, io
+// and bufio packages, so records are attributed to the code that wrote the line.
+func callerPC() uintptr {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+
+	for _, pc := range pcs {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+		pkg, _, _ := strings.Cut(f.Function, ".")
+		switch pkg {
+		case "log", "fmt", "io", "bufio":
+			continue
+		}
+
+		return pc
+	}
+
+	return 0
+}
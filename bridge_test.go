@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   WriterOptions
+		writes []string
+		want   [][2]string // level and msg of each record
+	}{
+		{
+			name:   "one record per line",
+			writes: []string{"first\nsecond\n"},
+			want:   [][2]string{{"info", "first"}, {"info", "second"}},
+		},
+		{
+			name:   "partial lines are joined",
+			writes: []string{"hel", "lo\nwor", "ld\n", "pending"},
+			want:   [][2]string{{"info", "hello"}, {"info", "world"}},
+		},
+		{
+			name:   "blank lines and carriage returns",
+			writes: []string{"\n  \nwindows\r\n"},
+			want:   [][2]string{{"info", "windows"}},
+		},
+		{
+			name:   "configured level",
+			opts:   WriterOptions{Level: "warn"},
+			writes: []string{"[ERROR] not parsed\n"},
+			want:   [][2]string{{"warn", "[ERROR] not parsed"}},
+		},
+		{
+			name: "level prefixes",
+			opts: WriterOptions{ParseLevel: true},
+			writes: []string{
+				"[WARN] disk almost full\n",
+				"[warning]: retrying\n",
+				"ERROR: failed\n",
+				"err: short form\n",
+				"[DEBUG] hidden\n",
+				"Warning without colon\n",
+			},
+			want: [][2]string{
+				{"warn", "disk almost full"},
+				{"warn", "retrying"},
+				{"error", "failed"},
+				{"error", "short form"},
+				{"info", "Warning without colon"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w := NewWriter(newLogger(&buf, Options{Format: "json"}), tt.opts)
+			for _, s := range tt.writes {
+				if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(s))
+				}
+			}
+
+			lines := decodeLines(t, buf.String())
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d records %v, want %d", len(lines), lines, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if lines[i]["level"] != want[0] || lines[i]["msg"] != want[1] {
+					t.Errorf("record %d = %v %q, want %v %q", i, lines[i]["level"], lines[i]["msg"], want[0], want[1])
+				}
+			}
+		})
+	}
+}
+
+func TestNewStdLogger_Source(t *testing.T) {
+	var buf bytes.Buffer
+
+	std := NewStdLogger(newLogger(&buf, Options{Format: "json", AddSource: true}), WriterOptions{})
+	std.Printf("value %d", 42)
+
+	out := decodeLines(t, buf.String())[0]
+	if out["msg"] != "value 42" {
+		t.Errorf("msg = %v, want value 42", out["msg"])
+	}
+	if src, _ := out["source"].(string); !strings.Contains(src, "bridge_test.go") {
+		t.Errorf("source = %v, want the caller of log.Printf", out["source"])
+	}
+
+	buf.Reset()
+	fmt.Fprintln(NewWriter(newLogger(&buf, Options{Format: "json", AddSource: true}), WriterOptions{}), "direct")
+
+	out = decodeLines(t, buf.String())[0]
+	if src, _ := out["source"].(string); !strings.Contains(src, "bridge_test.go") {
+		t.Errorf("source = %v, want the caller of fmt.Fprintln", out["source"])
+	}
+}
+
+func TestSetGlobalLogger_StdLog(t *testing.T) {
+	originalLogger := slog.Default()
+	originalWriter, originalFlags, originalPrefix := log.Writer(), log.Flags(), log.Prefix()
+	defer func() {
+		slog.SetDefault(originalLogger)
+		log.SetOutput(originalWriter)
+		log.SetFlags(originalFlags)
+		log.SetPrefix(originalPrefix)
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	SetGlobalLogger(Options{Format: "json"})
+	log.Print("[WARN] from the log package")
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 1 || lines[0]["level"] != "warn" || lines[0]["msg"] != "from the log package" {
+		t.Errorf("lines = %v, want one warn record", lines)
+	}
+}
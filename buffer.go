@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Defaults of BufferOptions.
+const (
+	DefaultBufferSize    = 64 * 1024   // DefaultBufferSize is the flush threshold in bytes
+	DefaultBufferLatency = time.Second // DefaultBufferLatency is the maximum time records stay buffered
+)
+
+// BufferOptions configures NewBufferedWriter.
+type BufferOptions struct {
+	Size    int           // Size is the number of buffered bytes that triggers a flush (default 64 KiB)
+	Latency time.Duration // Latency is the maximum time a record stays buffered before it is flushed (default 1s)
+}
+
+// BufferedWriter buffers writes to an underlying writer, so that a batch of records
+// costs a single write. The buffer is flushed when it reaches the size threshold,
+// when the oldest buffered record reaches the maximum latency, on Flush and on Close.
+//
+// Write never fails because of the underlying writer: data it does not accept stays
+// buffered and is retried by the next flush, and the error is returned by the next
+// Flush or Close.
+//
+// A Handler writing to a BufferedWriter flushes it after every error-level record,
+// and flushes and closes it on Handler.Close.
+type BufferedWriter struct {
+	w       io.Writer
+	size    int
+	latency time.Duration
+
+	mu     sync.Mutex
+	buf    []byte
+	timer  *time.Timer
+	err    error // err is the first error of the underlying writer since the last Flush
+	closed bool
+}
+
+// NewBufferedWriter returns a BufferedWriter writing to w.
+func NewBufferedWriter(w io.Writer, opts BufferOptions) *BufferedWriter {
+	if opts.Size <= 0 {
+		opts.Size = DefaultBufferSize
+	}
+	if opts.Latency <= 0 {
+		opts.Latency = DefaultBufferLatency
+	}
+
+	return &BufferedWriter{
+		w:       w,
+		size:    opts.Size,
+		latency: opts.Latency,
+		buf:     make([]byte, 0, opts.Size),
+	}
+}
+
+// Write buffers p. Writes larger than the buffer are passed through after flushing it.
+func (b *BufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, ErrClosed
+	}
+
+	n := len(p)
+
+	if len(b.buf)+len(p) > b.size {
+		b.keepErr(b.flush())
+	}
+
+	// unwritten data of a failed flush goes first
+	if len(p) >= b.size && len(b.buf) == 0 {
+		written, err := b.w.Write(p)
+		if err == nil && written < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err == nil {
+			return n, nil
+		}
+
+		b.keepErr(err)
+		p = p[written:]
+	}
+
+	b.buf = append(b.buf, p...)
+
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.latency, b.flushTimed)
+	}
+
+	return n, nil
+}
+
+// Flush writes the buffered data to the underlying writer. It implements Flusher.
+func (b *BufferedWriter) Flush(_ context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return errors.Join(b.takeErr(), b.flush())
+}
+
+// Close flushes the buffer and closes the underlying writer if it is an io.Closer
+// other than os.Stdout and os.Stderr. Further writes fail with ErrClosed.
+func (b *BufferedWriter) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+
+	return errors.Join(b.takeErr(), b.flush(), closeWriter(b.w))
+}
+
+// flushTimed flushes the buffer once the maximum latency has passed.
+func (b *BufferedWriter) flushTimed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.keepErr(b.flush())
+}
+
+// flush writes the buffered data and stops the latency timer. Data the underlying
+// writer did not accept stays buffered. b.mu must be held.
+func (b *BufferedWriter) flush() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	if len(b.buf) == 0 {
+		return nil
+	}
+
+	n, err := b.w.Write(b.buf)
+	if err == nil && n < len(b.buf) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		b.buf = b.buf[:copy(b.buf, b.buf[n:])]
+		return err
+	}
+
+	b.buf = b.buf[:0]
+
+	return nil
+}
+
+// keepErr records err to be returned by the next Flush or Close, unless an earlier
+// error is pending. b.mu must be held.
+func (b *BufferedWriter) keepErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// takeErr returns and clears the pending error. b.mu must be held.
+func (b *BufferedWriter) takeErr() error {
+	err := b.err
+	b.err = nil
+
+	return err
+}
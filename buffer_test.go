@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter records the writes it receives and is safe for concurrent use.
+type countingWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	closed bool
+	err    error
+	max    int // max limits the bytes accepted by a write, if not 0
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.writes++
+
+	if w.max > 0 && len(p) > w.max {
+		p = p[:w.max]
+	}
+
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+
+	return nil
+}
+
+func (w *countingWriter) state() (string, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String(), w.writes
+}
+
+func TestBufferedWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       BufferOptions
+		writes     []string
+		flush      bool
+		wantOut    string
+		wantWrites int
+	}{
+		{
+			name:       "buffered below threshold",
+			opts:       BufferOptions{Size: 16, Latency: time.Hour},
+			writes:     []string{"abc", "def"},
+			wantOut:    "",
+			wantWrites: 0,
+		},
+		{
+			name:       "flush on threshold",
+			opts:       BufferOptions{Size: 8, Latency: time.Hour},
+			writes:     []string{"abcd", "efgh", "ij"},
+			wantOut:    "abcdefgh",
+			wantWrites: 1,
+		},
+		{
+			name:       "large writes pass through",
+			opts:       BufferOptions{Size: 4, Latency: time.Hour},
+			writes:     []string{"ab", "cdefgh"},
+			wantOut:    "abcdefgh",
+			wantWrites: 2,
+		},
+		{
+			name:       "explicit flush",
+			opts:       BufferOptions{Size: 16, Latency: time.Hour},
+			writes:     []string{"abc", "def"},
+			flush:      true,
+			wantOut:    "abcdef",
+			wantWrites: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &countingWriter{}
+			b := NewBufferedWriter(w, tt.opts)
+
+			for _, s := range tt.writes {
+				if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(s))
+				}
+			}
+
+			if tt.flush {
+				if err := b.Flush(context.Background()); err != nil {
+					t.Fatalf("Flush() error = %v", err)
+				}
+			}
+
+			if out, writes := w.state(); out != tt.wantOut || writes != tt.wantWrites {
+				t.Errorf("output, writes = %q, %d, want %q, %d", out, writes, tt.wantOut, tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestBufferedWriter_Latency(t *testing.T) {
+	w := &countingWriter{}
+	b := NewBufferedWriter(w, BufferOptions{Latency: 10 * time.Millisecond})
+
+	b.Write([]byte("record\n"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if out, _ := w.state(); out == "record\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("buffer was not flushed after the maximum latency")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBufferedWriter_Errors(t *testing.T) {
+	boom := errors.New("boom")
+	w := &countingWriter{err: boom}
+	b := NewBufferedWriter(w, BufferOptions{Latency: time.Millisecond})
+
+	b.Write([]byte("first\n"))
+
+	// the timed flush fails and keeps the record
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b.mu.Lock()
+		err := b.err
+		b.mu.Unlock()
+
+		if err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed flush did not fail")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if n, err := b.Write([]byte("second\n")); n != 7 || err != nil {
+		t.Errorf("Write() = %d, %v, want the record buffered", n, err)
+	}
+
+	w.mu.Lock()
+	w.err = nil
+	w.mu.Unlock()
+
+	// the error is reported once by Flush, the records are delivered
+	if err := b.Flush(context.Background()); !errors.Is(err, boom) {
+		t.Errorf("Flush() error = %v, want %v", err, boom)
+	}
+	if out, _ := w.state(); out != "first\nsecond\n" {
+		t.Errorf("output = %q, want both records", out)
+	}
+	if err := b.Close(); err != nil {
+		t.Errorf("Close() error = %v, want nil", err)
+	}
+}
+
+func TestBufferedWriter_ShortWrite(t *testing.T) {
+	w := &countingWriter{max: 4}
+	b := NewBufferedWriter(w, BufferOptions{Size: 8, Latency: time.Hour})
+
+	// too large for the buffer, passed through and partially written
+	b.Write([]byte("0123456789"))
+	b.Write([]byte("ab"))
+
+	if err := b.Flush(context.Background()); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("Flush() error = %v, want %v", err, io.ErrShortWrite)
+	}
+
+	w.mu.Lock()
+	w.max = 0
+	w.mu.Unlock()
+
+	if err := b.Close(); err != nil {
+		t.Errorf("Close() error = %v, want nil", err)
+	}
+	if out, _ := w.state(); out != "0123456789ab" {
+		t.Errorf("output = %q, want the unwritten bytes kept in order", out)
+	}
+}
+
+func TestBufferedWriter_Handler(t *testing.T) {
+	w := &countingWriter{}
+	b := NewBufferedWriter(w, BufferOptions{Latency: time.Hour})
+
+	l := NewLogger(Options{Format: "json", Level: "debug", Output: b})
+	handler := l.Handler().(*Handler)
+
+	l.Debug("first")
+	l.Warn("second")
+
+	if out, _ := w.state(); out != "" {
+		t.Fatalf("output = %q, want info and warn records buffered", out)
+	}
+
+	l.Error("failed")
+
+	out, writes := w.state()
+	if writes != 1 || strings.Count(out, "\n") != 3 {
+		t.Errorf("output, writes = %q, %d, want all records in a single write after the error", out, writes)
+	}
+
+	l.Info("last")
+
+	if err := handler.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if out, _ := w.state(); !strings.Contains(out, `"msg":"last"`) {
+		t.Errorf("output = %q, want the buffered record flushed on Close", out)
+	}
+	if out, _ := w.state(); !strings.Contains(out, `"level":"debug"`) {
+		t.Errorf("output = %q, want the options of NewLogger applied", out)
+	}
+	if !w.closed {
+		t.Error("underlying writer should be closed")
+	}
+	if _, err := b.Write([]byte("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write() after Close error = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Extractor derives attributes from the context of a record, e.g. a request ID set by middleware.
+// Extractors run on every Handle call for enabled levels and must be safe for concurrent use.
+type Extractor func(ctx context.Context) []slog.Attr
+
+// ctxAttrsKey is the context key for attributes stored by WithContextAttrs.
+type ctxAttrsKey struct{}
+
+// WithContextAttrs returns a copy of ctx carrying attrs in addition to the attributes
+// already stored in ctx. Handler adds them to every record logged with this context,
+// e.g. through slog.InfoContext. If a key is stored more than once, the innermost
+// (most recently added) value wins.
+func WithContextAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) < 1 {
+		return ctx
+	}
+
+	parent := ContextAttrs(ctx)
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+
+	for _, a := range parent {
+		if !hasKey(attrs, a.Key) {
+			merged = append(merged, a)
+		}
+	}
+
+	for i, a := range attrs {
+		if !hasKey(attrs[i+1:], a.Key) {
+			merged = append(merged, a)
+		}
+	}
+
+	return context.WithValue(ctx, ctxAttrsKey{}, merged)
+}
+
+// ContextAttrs returns the attributes stored in ctx by WithContextAttrs.
+// The returned slice must not be modified.
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+
+	return attrs
+}
+
+// hasKey reports whether attrs contain an attribute with the given key.
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contextAttrs returns the attributes derived from ctx: those returned by the extractors,
+// followed by those stored with WithContextAttrs, which take precedence.
+// Extractors are skipped if the level is disabled.
+func (h *Handler) contextAttrs(ctx context.Context, level slog.Level) []slog.Attr {
+	stored := ContextAttrs(ctx)
+
+	if len(h.extractors) < 1 {
+		return stored
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !h.Enabled(ctx, level) {
+		return stored
+	}
+
+	var attrs []slog.Attr
+	for _, extract := range h.extractors {
+		attrs = append(attrs, extract(ctx)...)
+	}
+
+	return append(attrs, stored...)
+}
+
+// loggerKey is the context key for loggers stored by IntoContext.
+type loggerKey struct{}
+
+// IntoContext returns a copy of ctx carrying l. Functions further down the call chain
+// retrieve it with FromContext, and may store a derived logger, e.g. l.With(...), for their callees.
+func IntoContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by IntoContext.
+// If ctx carries no logger, slog.Default() is returned.
+func FromContext(ctx context.Context) *slog.Logger {
+	return FromContextOr(ctx, slog.Default())
+}
+
+// FromContextOr returns the logger stored in ctx by IntoContext, or fallback if ctx carries
+// no logger. Use slog.New(NewNullHandler()) as fallback to discard logs of callers that
+// did not provide a logger.
+func FromContextOr(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && l != nil {
+			return l
+		}
+	}
+
+	return fallback
+}
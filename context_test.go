@@ -0,0 +1,321 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+// requestIDExtractor returns the request ID stored in the context by middleware.
+func requestIDExtractor(ctx context.Context) []slog.Attr {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return []slog.Attr{slog.String("request_id", id)}
+	}
+
+	return nil
+}
+
+func TestWithContextAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func() context.Context
+		logFunc  func(ctx context.Context, logger *slog.Logger)
+		validate func(t *testing.T, out map[string]any)
+	}{
+		{
+			name: "attrs are added to records",
+			ctx: func() context.Context {
+				return WithContextAttrs(context.Background(), slog.String("request_id", "r1"))
+			},
+			logFunc: func(ctx context.Context, logger *slog.Logger) {
+				logger.InfoContext(ctx, "test")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["request_id"] != "r1" {
+					t.Errorf("request_id = %v, want r1", out["request_id"])
+				}
+			},
+		},
+		{
+			name: "nested contexts accumulate",
+			ctx: func() context.Context {
+				ctx := WithContextAttrs(context.Background(), slog.String("request_id", "r1"))
+				return WithContextAttrs(ctx, slog.String("user", "bob"))
+			},
+			logFunc: func(ctx context.Context, logger *slog.Logger) {
+				logger.InfoContext(ctx, "test")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["request_id"] != "r1" || out["user"] != "bob" {
+					t.Errorf("out = %v, want request_id and user", out)
+				}
+			},
+		},
+		{
+			name: "innermost value wins",
+			ctx: func() context.Context {
+				ctx := WithContextAttrs(context.Background(), slog.String("tenant", "outer"))
+				return WithContextAttrs(ctx, slog.String("tenant", "inner"))
+			},
+			logFunc: func(ctx context.Context, logger *slog.Logger) {
+				logger.InfoContext(ctx, "test")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["tenant"] != "inner" {
+					t.Errorf("tenant = %v, want inner", out["tenant"])
+				}
+			},
+		},
+		{
+			name: "record attrs take precedence",
+			ctx: func() context.Context {
+				return WithContextAttrs(context.Background(), slog.String("user", "ctx"))
+			},
+			logFunc: func(ctx context.Context, logger *slog.Logger) {
+				logger.InfoContext(ctx, "test", "user", "call")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["user"] != "call" {
+					t.Errorf("user = %v, want call", out["user"])
+				}
+			},
+		},
+		{
+			name: "attrs stay at the top level",
+			ctx: func() context.Context {
+				return WithContextAttrs(context.Background(), slog.String("request_id", "r1"))
+			},
+			logFunc: func(ctx context.Context, logger *slog.Logger) {
+				logger.WithGroup("g").With("a", 1).InfoContext(ctx, "test", "b", 2)
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["request_id"] != "r1" {
+					t.Errorf("request_id = %v, want r1", out["request_id"])
+				}
+				if g, _ := out["g"].(map[string]any); g["a"] != 1.0 || g["b"] != 2.0 {
+					t.Errorf("g = %v, want a and b", out["g"])
+				}
+			},
+		},
+		{
+			name: "no attrs without context",
+			ctx:  context.Background,
+			logFunc: func(ctx context.Context, logger *slog.Logger) {
+				logger.Info("test")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if len(out) != 3 {
+					t.Errorf("out = %v, want only standard fields", out)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.logFunc(tt.ctx(), newLogger(&buf, Options{Format: "json"}))
+
+			out := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			tt.validate(t, out)
+		})
+	}
+}
+
+func TestContextAttrs(t *testing.T) {
+	ctx := WithContextAttrs(context.Background(), slog.Int("a", 1), slog.Int("b", 2), slog.Int("a", 3))
+	ctx = WithContextAttrs(ctx, slog.Int("b", 4))
+
+	got := ContextAttrs(ctx)
+	want := []slog.Attr{slog.Int("a", 3), slog.Int("b", 4)}
+
+	if len(got) != len(want) {
+		t.Fatalf("ContextAttrs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("ContextAttrs()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if ContextAttrs(context.Background()) != nil {
+		t.Error("ContextAttrs() should be empty without stored attrs")
+	}
+}
+
+func TestHandler_Extractors(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		calls int
+	)
+
+	opts := Options{
+		HandlerOptions: &slog.HandlerOptions{Level: slog.LevelInfo},
+		Format:         "json",
+		Extractors: []Extractor{
+			requestIDExtractor,
+			func(context.Context) []slog.Attr {
+				calls++
+				return []slog.Attr{slog.String("tenant", "extracted")}
+			},
+		},
+	}
+
+	handler := NewHandler(&buf, &opts)
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r1")
+	ctx = WithContextAttrs(ctx, slog.String("tenant", "stored"))
+
+	slog.New(&handler).InfoContext(ctx, "test")
+
+	out := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+
+	if out["request_id"] != "r1" {
+		t.Errorf("request_id = %v, want r1", out["request_id"])
+	}
+	if out["tenant"] != "stored" {
+		t.Errorf("tenant = %v, want stored attrs to take precedence", out["tenant"])
+	}
+	if calls != 1 {
+		t.Errorf("extractor calls = %d, want 1", calls)
+	}
+
+	// Handle called directly for a disabled level must not run extractors
+	buf.Reset()
+	handler.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelDebug, "debug", 0))
+
+	if calls != 1 {
+		t.Errorf("extractor calls = %d, want extractors skipped for disabled level", calls)
+	}
+}
+
+func BenchmarkHandler_Extractors(b *testing.B) {
+	benchmarks := []struct {
+		name       string
+		extractors []Extractor
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:       "request id",
+			extractors: []Extractor{requestIDExtractor},
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r1")
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			opts := Options{
+				Format:     "json",
+				Extractors: bm.extractors,
+			}
+
+			handler := NewHandler(io.Discard, &opts)
+			logger := slog.New(&handler)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.InfoContext(ctx, "benchmark message", "key", "value")
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	stored := slog.New(NewNullHandler()).With("scope", "request")
+	fallback := slog.New(NewNullHandler())
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		want         *slog.Logger
+		wantFallback *slog.Logger
+	}{
+		{
+			name:         "stored logger",
+			ctx:          IntoContext(context.Background(), stored),
+			want:         stored,
+			wantFallback: stored,
+		},
+		{
+			name:         "empty context",
+			ctx:          context.Background(),
+			want:         slog.Default(),
+			wantFallback: fallback,
+		},
+		{
+			name:         "nil logger",
+			ctx:          IntoContext(context.Background(), nil),
+			want:         slog.Default(),
+			wantFallback: fallback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %p, want %p", got, tt.want)
+			}
+			if got := FromContextOr(tt.ctx, fallback); got != tt.wantFallback {
+				t.Errorf("FromContextOr() = %p, want %p", got, tt.wantFallback)
+			}
+		})
+	}
+}
+
+// loggingExtractorKey marks contexts of records logged by an extractor.
+type loggingExtractorKey struct{}
+
+func TestHandler_ExtractorLogs(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		logger *slog.Logger
+	)
+
+	opts := Options{
+		Format: "json",
+		Extractors: []Extractor{
+			func(ctx context.Context) []slog.Attr {
+				if ctx.Value(loggingExtractorKey{}) == nil {
+					logger.InfoContext(context.WithValue(ctx, loggingExtractorKey{}, true), "from extractor")
+				}
+				return []slog.Attr{slog.String("tenant", "t1")}
+			},
+		},
+	}
+
+	logger = newLogger(&buf, opts)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Info("test")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging from an extractor deadlocked")
+	}
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 2 || lines[0]["msg"] != "from extractor" || lines[1]["msg"] != "test" {
+		t.Errorf("lines = %v, want the extractor record followed by the record", lines)
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCrashOutputUnsupported is returned by MonitorCrashes on Go versions before 1.23.
+var ErrCrashOutputUnsupported = errors.New("crash output requires Go 1.23 or later")
+
+// crashMonitorEnv marks the monitor process started by MonitorCrashes.
+const crashMonitorEnv = "SLOG_HANDLER_CRASH_MONITOR"
+
+// logCrash reads the crash report of a Go program from report and logs it to l.
+// Nothing is logged if report is empty, that is if the program exited without crashing.
+func logCrash(l *slog.Logger, report io.Reader) {
+	b, _ := io.ReadAll(report)
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return
+	}
+
+	msg, goroutine, frames := parseCrash(string(b))
+
+	// the source is unknown, the crashing process has already exited
+	r := slog.NewRecord(time.Now(), LevelFatal, "crash", 0)
+	r.AddAttrs(
+		slog.String("error", msg),
+		slog.String("goroutine", goroutine),
+		slog.Any("stack", frames),
+	)
+
+	_ = l.Handler().Handle(context.Background(), r)
+}
+
+// parseCrash splits a crash report into the panic or fatal error message, the header of
+// the first goroutine and its frames.
+func parseCrash(report string) (msg, goroutine string, frames []Frame) {
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(strings.NewReader(report))
+	)
+
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	i := 0
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) == ""; i++ {
+	}
+
+	var message []string
+	for ; i < len(lines) && !strings.HasPrefix(lines[i], "goroutine "); i++ {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			message = append(message, line)
+		}
+	}
+	msg = strings.Join(message, "\n")
+
+	if i == len(lines) {
+		return msg, "", nil
+	}
+
+	goroutine = strings.TrimSuffix(lines[i], ":")
+
+	for i++; i+1 < len(lines) && lines[i] != ""; i += 2 {
+		function := lines[i]
+		if strings.HasPrefix(function, "created by ") {
+			function, _, _ = strings.Cut(strings.TrimPrefix(function, "created by "), " in goroutine ")
+		} else if j := strings.LastIndex(function, "("); j > 0 && strings.HasSuffix(function, ")") {
+			function = function[:j]
+		}
+
+		location, _, _ := strings.Cut(strings.TrimSpace(lines[i+1]), " ")
+
+		f := Frame{Function: function, File: location}
+		if j := strings.LastIndex(location, ":"); j > 0 {
+			if n, err := strconv.Atoi(location[j+1:]); err == nil {
+				f.File, f.Line = location[:j], n
+			}
+		}
+
+		frames = append(frames, f)
+	}
+
+	return msg, goroutine, frames
+}
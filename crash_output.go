@@ -0,0 +1,9 @@
+//go:build !go1.23
+
+package logger
+
+// MonitorCrashes routes crash reports of unrecovered panics through the logger.
+// It requires Go 1.23 or later and returns ErrCrashOutputUnsupported otherwise.
+func MonitorCrashes(_ Options) error {
+	return ErrCrashOutputUnsupported
+}
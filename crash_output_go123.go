@@ -0,0 +1,64 @@
+//go:build go1.23
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime/debug"
+)
+
+// MonitorCrashes routes crash reports of unrecovered panics and fatal errors through
+// a logger created with opts, so that they are logged as a single record with the
+// panic message and the stack of the crashing goroutine.
+//
+// It starts the executable again as a monitor process and passes the crash report to
+// it with debug.SetCrashOutput. It must be called at the very beginning of main, where
+// the monitor process logs the report and exits without running the rest of main:
+//
+//	func main() {
+//		if err := logger.MonitorCrashes(opts); err != nil {
+//			slog.Warn("crash monitor not started", "error", err)
+//		}
+//		...
+//	}
+//
+// The Go runtime still writes the report to standard error as well.
+func MonitorCrashes(opts Options) error {
+	if os.Getenv(crashMonitorEnv) != "" {
+		logCrash(NewLogger(opts), os.Stdin)
+		os.Exit(0)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("crash monitor: %w", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("crash monitor: %w", err)
+	}
+	defer w.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), crashMonitorEnv+"=1")
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Start()
+	r.Close()
+	if err != nil {
+		return fmt.Errorf("crash monitor: %w", err)
+	}
+
+	// the runtime keeps its own duplicate of w, the monitor reads until it is closed on exit
+	if err := debug.SetCrashOutput(w, debug.CrashOptions{}); err != nil {
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("crash monitor: %w", err)
+	}
+
+	return nil
+}
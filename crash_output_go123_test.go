@@ -0,0 +1,73 @@
+//go:build go1.23
+
+package logger
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitorCrashes(t *testing.T) {
+	if os.Getenv("TEST_MONITOR_CRASHES") != "" {
+		if err := MonitorCrashes(Options{Format: "json"}); err != nil {
+			t.Fatalf("MonitorCrashes() error = %v", err)
+		}
+		panic("unrecovered")
+	}
+
+	if testing.Short() {
+		t.Skip("starts subprocesses")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMonitorCrashes$")
+	cmd.Env = append(os.Environ(), "TEST_MONITOR_CRASHES=1")
+
+	// the monitor inherits stdout and keeps writing after the crashed process exits
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stdout = w
+
+	if err := cmd.Run(); err == nil {
+		t.Fatal("process should have crashed")
+	}
+	w.Close()
+
+	done := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		done <- buf.Bytes()
+	}()
+
+	var out []byte
+	select {
+	case out = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the crash monitor")
+	}
+
+	// the output of the test binary is interleaved with records
+	var crash map[string]any
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		if record := decodeLines(t, line)[0]; record["msg"] == "crash" {
+			crash = record
+		}
+	}
+
+	if crash == nil {
+		t.Fatalf("output = %q, want a crash record", out)
+	}
+	if e, _ := crash["error"].(string); !strings.Contains(e, "panic: unrecovered") {
+		t.Errorf("error = %v, want the panic message", crash["error"])
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testCrashReport = `panic: boom [recovered]
+	panic: again
+
+goroutine 7 [running]:
+main.(*worker).run(0xc000012345, {0x4b1e20, 0x3})
+	/src/app/worker.go:42 +0x1d
+main.main()
+	/src/app/main.go:10 +0x25
+created by main.start in goroutine 1
+	/src/app/main.go:20 +0x4f
+
+goroutine 1 [chan receive]:
+main.main()
+	/src/app/main.go:12 +0x30
+`
+
+func TestParseCrash(t *testing.T) {
+	tests := []struct {
+		name          string
+		report        string
+		wantMsg       string
+		wantGoroutine string
+		wantFrames    []Frame
+	}{
+		{
+			name:          "panic",
+			report:        testCrashReport,
+			wantMsg:       "panic: boom [recovered]\npanic: again",
+			wantGoroutine: "goroutine 7 [running]",
+			wantFrames: []Frame{
+				{Function: "main.(*worker).run", File: "/src/app/worker.go", Line: 42},
+				{Function: "main.main", File: "/src/app/main.go", Line: 10},
+				{Function: "main.start", File: "/src/app/main.go", Line: 20},
+			},
+		},
+		{
+			name:    "fatal error without goroutines",
+			report:  "\nfatal error: out of memory\n",
+			wantMsg: "fatal error: out of memory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, goroutine, frames := parseCrash(tt.report)
+
+			if msg != tt.wantMsg {
+				t.Errorf("msg = %q, want %q", msg, tt.wantMsg)
+			}
+			if goroutine != tt.wantGoroutine {
+				t.Errorf("goroutine = %q, want %q", goroutine, tt.wantGoroutine)
+			}
+			if len(frames) != len(tt.wantFrames) {
+				t.Fatalf("frames = %v, want %v", frames, tt.wantFrames)
+			}
+			for i := range frames {
+				if frames[i] != tt.wantFrames[i] {
+					t.Errorf("frames[%d] = %v, want %v", i, frames[i], tt.wantFrames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLogCrash(t *testing.T) {
+	var buf bytes.Buffer
+
+	logCrash(newLogger(&buf, Options{Format: "json"}), strings.NewReader(""))
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want nothing for an empty report", buf.String())
+	}
+
+	logCrash(newLogger(&buf, Options{Format: "json"}), strings.NewReader(testCrashReport))
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want 1", len(lines))
+	}
+	if lines[0]["level"] != "fatal" || lines[0]["msg"] != "crash" || lines[0]["goroutine"] != "goroutine 7 [running]" {
+		t.Errorf("out = %v, want a crash record", lines[0])
+	}
+	if stack, _ := lines[0]["stack"].([]any); len(stack) != 3 {
+		t.Errorf("stack = %v, want 3 frames", lines[0]["stack"])
+	}
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Directive transforms the value of an attribute whose key is prefixed with the directive name.
+// A key has the form "name;name=arg;key": every leading segment naming a registered directive
+// is stripped from the key and applied from left to right. arg holds the text after "=",
+// or is empty if the directive has no argument.
+// Returning false drops the attribute from the record.
+type Directive func(arg string, v slog.Value) (slog.Value, bool)
+
+var (
+	directivesMu sync.RWMutex
+
+	// directives holds the registered key directives by name.
+	directives = map[string]Directive{
+		"raw":       rawDirective,
+		"json":      jsonDirective,
+		"hex":       hexDirective,
+		"base64":    base64Directive,
+		"secret":    secretDirective,
+		"trunc":     truncDirective,
+		"omitempty": omitemptyDirective,
+	}
+)
+
+// RegisterDirective registers a key directive under name, replacing any directive
+// already registered with the same name, including the built-in ones.
+// Names must not contain ";" or "=".
+func RegisterDirective(name string, d Directive) {
+	directivesMu.Lock()
+	defer directivesMu.Unlock()
+
+	directives[name] = d
+}
+
+// applyDirectives strips directive prefixes from the attribute key and applies them to its value.
+// The second return value is false if a directive dropped the attribute.
+func applyDirectives(a slog.Attr) (slog.Attr, bool) {
+	if !strings.Contains(a.Key, ";") {
+		return a, true
+	}
+
+	directivesMu.RLock()
+	defer directivesMu.RUnlock()
+
+	segments := strings.Split(a.Key, ";")
+
+	i := 0
+	for ; i < len(segments)-1; i++ {
+		name, arg, _ := strings.Cut(segments[i], "=")
+
+		d, ok := directives[name]
+		if !ok {
+			break
+		}
+
+		if a.Value, ok = d(arg, a.Value); !ok {
+			return slog.Attr{}, false
+		}
+	}
+
+	a.Key = strings.Join(segments[i:], ";")
+
+	return a, true
+}
+
+// rawDirective renders the value using Go syntax, as with the %#v verb.
+// Cyclic values and panicking methods are reported inline instead of crashing the caller.
+func rawDirective(_ string, v slog.Value) (slog.Value, bool) {
+	return slog.StringValue(sprintRaw(v.Any())), true
+}
+
+// jsonDirective renders the value as a string containing its JSON encoding.
+func jsonDirective(_ string, v slog.Value) (slog.Value, bool) {
+	b, err := json.Marshal(v.Any())
+	if err != nil {
+		return slog.StringValue("!ERROR:" + err.Error()), true
+	}
+
+	return slog.StringValue(string(b)), true
+}
+
+// hexDirective renders strings and byte slices as hexadecimal.
+func hexDirective(_ string, v slog.Value) (slog.Value, bool) {
+	return slog.StringValue(hex.EncodeToString(valueBytes(v))), true
+}
+
+// base64Directive renders strings and byte slices using standard base64 encoding.
+func base64Directive(_ string, v slog.Value) (slog.Value, bool) {
+	return slog.StringValue(base64.StdEncoding.EncodeToString(valueBytes(v))), true
+}
+
+// secretDirective replaces the value with a redaction marker.
+func secretDirective(_ string, _ slog.Value) (slog.Value, bool) {
+	return slog.StringValue("[REDACTED]"), true
+}
+
+// truncDirective shortens the string form of the value to at most arg runes,
+// appending an ellipsis if anything was cut off.
+func truncDirective(arg string, v slog.Value) (slog.Value, bool) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return v, true
+	}
+
+	return slog.StringValue(truncate(v.String(), n)), true
+}
+
+// omitemptyDirective drops the attribute if its value is the zero value of its type,
+// an empty string, slice, map or group.
+func omitemptyDirective(_ string, v slog.Value) (slog.Value, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		return v, v.String() != ""
+	case slog.KindInt64:
+		return v, v.Int64() != 0
+	case slog.KindUint64:
+		return v, v.Uint64() != 0
+	case slog.KindFloat64:
+		return v, v.Float64() != 0
+	case slog.KindBool:
+		return v, v.Bool()
+	case slog.KindDuration:
+		return v, v.Duration() != 0
+	case slog.KindTime:
+		return v, !v.Time().IsZero()
+	case slog.KindGroup:
+		return v, len(v.Group()) > 0
+	}
+
+	if v.Any() == nil {
+		return v, false
+	}
+
+	rv := reflect.ValueOf(v.Any())
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v, rv.Len() > 0
+	}
+
+	return v, !rv.IsZero()
+}
+
+// valueBytes returns the bytes of a string or byte slice value, or of its string form otherwise.
+func valueBytes(v slog.Value) []byte {
+	if b, ok := v.Any().([]byte); ok {
+		return b
+	}
+
+	return []byte(v.String())
+}
+
+// truncate shortens s to at most n runes, appending an ellipsis if anything was cut off.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j] + "…"
+		}
+		i++
+	}
+
+	return s
+}
@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestApplyDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		attr     slog.Attr
+		wantKey  string
+		wantVal  string
+		wantDrop bool
+	}{
+		{
+			name:    "no directive",
+			attr:    slog.String("key", "value"),
+			wantKey: "key",
+			wantVal: "value",
+		},
+		{
+			name:    "unknown prefix is kept",
+			attr:    slog.String("a;b", "value"),
+			wantKey: "a;b",
+			wantVal: "value",
+		},
+		{
+			name:    "raw",
+			attr:    slog.Any("raw;key", map[int]string{0: "test"}),
+			wantKey: "key",
+			wantVal: `map[int]string{0:"test"}`,
+		},
+		{
+			name:    "json",
+			attr:    slog.Any("json;key", map[string]int{"a": 1}),
+			wantKey: "key",
+			wantVal: `{"a":1}`,
+		},
+		{
+			name:    "hex",
+			attr:    slog.Any("hex;key", []byte{0xde, 0xad}),
+			wantKey: "key",
+			wantVal: "dead",
+		},
+		{
+			name:    "base64",
+			attr:    slog.String("base64;key", "hi"),
+			wantKey: "key",
+			wantVal: "aGk=",
+		},
+		{
+			name:    "secret",
+			attr:    slog.String("secret;password", "hunter2"),
+			wantKey: "password",
+			wantVal: "[REDACTED]",
+		},
+		{
+			name:    "trunc",
+			attr:    slog.String("trunc=3;key", "abcdef"),
+			wantKey: "key",
+			wantVal: "abc…",
+		},
+		{
+			name:    "trunc keeps short values",
+			attr:    slog.String("trunc=10;key", "abc"),
+			wantKey: "key",
+			wantVal: "abc",
+		},
+		{
+			name:     "omitempty drops empty string",
+			attr:     slog.String("omitempty;key", ""),
+			wantDrop: true,
+		},
+		{
+			name:     "omitempty drops nil slice",
+			attr:     slog.Any("omitempty;key", []string(nil)),
+			wantDrop: true,
+		},
+		{
+			name:    "omitempty keeps value",
+			attr:    slog.Int("omitempty;key", 1),
+			wantKey: "key",
+			wantVal: "1",
+		},
+		{
+			name:    "directives are chained",
+			attr:    slog.String("omitempty;trunc=2;key", "abc"),
+			wantKey: "key",
+			wantVal: "ab…",
+		},
+		{
+			name:    "remaining key may contain separator",
+			attr:    slog.String("raw;a;b", "v"),
+			wantKey: "a;b",
+			wantVal: `"v"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := applyDirectives(tt.attr)
+			if ok == tt.wantDrop {
+				t.Fatalf("applyDirectives() kept = %v, want %v", ok, !tt.wantDrop)
+			}
+			if tt.wantDrop {
+				return
+			}
+			if got.Key != tt.wantKey {
+				t.Errorf("key = %q, want %q", got.Key, tt.wantKey)
+			}
+			if got.Value.String() != tt.wantVal {
+				t.Errorf("value = %q, want %q", got.Value.String(), tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestRegisterDirective(t *testing.T) {
+	RegisterDirective("upper", func(_ string, v slog.Value) (slog.Value, bool) {
+		return slog.StringValue(strings.ToUpper(v.String())), true
+	})
+	defer func() {
+		directivesMu.Lock()
+		delete(directives, "upper")
+		directivesMu.Unlock()
+	}()
+
+	got, ok := applyDirectives(slog.String("upper;key", "value"))
+	if !ok {
+		t.Fatal("attribute should not be dropped")
+	}
+	if got.Key != "key" || got.Value.String() != "VALUE" {
+		t.Errorf("applyDirectives() = %v, want key=VALUE", got)
+	}
+}
+
+func TestDirectives_group(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want string
+	}{
+		{
+			name: "secret",
+			log:  func(l *slog.Logger) { l.Info("x", slog.Group("secret;creds", slog.String("pw", "hunter2"))) },
+			want: `"creds":"[REDACTED]"`,
+		},
+		{
+			name: "trunc",
+			log:  func(l *slog.Logger) { l.Info("x", slog.Group("trunc=3;g", slog.String("a", "bcdef"))) },
+			want: `"g":"[a=…"`,
+		},
+		{
+			name: "nested",
+			log: func(l *slog.Logger) {
+				l.Info("x", slog.Group("outer", slog.Group("secret;inner", slog.String("pw", "hunter2"))))
+			},
+			want: `"outer":{"inner":"[REDACTED]"}`,
+		},
+		{
+			name: "with attrs",
+			log:  func(l *slog.Logger) { l.With(slog.Group("secret;creds", slog.String("pw", "hunter2"))).Info("x") },
+			want: `"creds":"[REDACTED]"`,
+		},
+		{
+			name: "omitempty keeps group",
+			log:  func(l *slog.Logger) { l.Info("x", slog.Group("omitempty;g", slog.Int("a", 1))) },
+			want: `"g":{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			tt.log(newLogger(&buf, Options{Format: "json"}))
+
+			if got := buf.String(); !strings.Contains(got, tt.want) || strings.Contains(got, "hunter2") || strings.Contains(got, ";") {
+				t.Errorf("output = %s, want it to contain %s", got, tt.want)
+			}
+		})
+	}
+}
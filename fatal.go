@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Levels above slog.LevelError for records that end the goroutine or the process.
+const (
+	LevelPanic = slog.Level(12) // LevelPanic is the level of records logged by Panic
+	LevelFatal = slog.Level(16) // LevelFatal is the level of records logged by Fatal
+)
+
+// flushTimeout bounds the time Fatal and Panic spend flushing registered flushers.
+const flushTimeout = 5 * time.Second
+
+// Flusher is implemented by handlers and writers that buffer records and must be
+// flushed before the process exits.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+var (
+	exitMu    sync.Mutex
+	flushers  []Flusher
+	exitHooks []func()
+	exitFunc  = os.Exit
+)
+
+// RegisterFlusher registers f to be flushed by Fatal and Panic before the process exits.
+// The handler of the default logger, as set by SetGlobalLogger, is flushed without registration.
+func RegisterFlusher(f Flusher) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	flushers = append(flushers, f)
+}
+
+// RegisterExitHook registers hook to be run by Fatal before the process exits.
+// Hooks run in reverse order of registration, like deferred calls.
+func RegisterExitHook(hook func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	exitHooks = append(exitHooks, hook)
+}
+
+// SetExitFunc replaces the function Fatal calls to exit the process, os.Exit by default,
+// e.g. to test code paths calling Fatal. A nil exit restores os.Exit.
+func SetExitFunc(exit func(code int)) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	exitFunc = exit
+}
+
+// Fatal logs a record at LevelFatal to l, runs the registered exit hooks,
+// flushes the registered flushers and exits the process with status 1.
+func Fatal(l *slog.Logger, msg string, args ...any) {
+	LogDepth(context.Background(), l, 1, LevelFatal, msg, args...)
+	exit(1)
+}
+
+// FatalContext is like Fatal, but logs with ctx.
+func FatalContext(ctx context.Context, l *slog.Logger, msg string, args ...any) {
+	LogDepth(ctx, l, 1, LevelFatal, msg, args...)
+	exit(1)
+}
+
+// Panic logs a record at LevelPanic to l, flushes the registered flushers and panics with msg.
+// Exit hooks are not run, as the panic may be recovered.
+func Panic(l *slog.Logger, msg string, args ...any) {
+	LogDepth(context.Background(), l, 1, LevelPanic, msg, args...)
+	_ = flushAll()
+	panic(msg)
+}
+
+// PanicContext is like Panic, but logs with ctx.
+func PanicContext(ctx context.Context, l *slog.Logger, msg string, args ...any) {
+	LogDepth(ctx, l, 1, LevelPanic, msg, args...)
+	_ = flushAll()
+	panic(msg)
+}
+
+// exit runs the exit hooks, so that records they log are flushed as well,
+// flushes the registered flushers and exits with code.
+func exit(code int) {
+	exitMu.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exit := exitFunc
+	exitMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+
+	_ = flushAll()
+
+	exit(code)
+}
+
+// flushAll flushes the registered flushers and the handler of the default logger
+// within flushTimeout and returns their errors.
+func flushAll() error {
+	exitMu.Lock()
+	fs := append([]Flusher{}, flushers...)
+	exitMu.Unlock()
+
+	if f, ok := slog.Default().Handler().(Flusher); ok {
+		fs = append(fs, f)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, f := range fs {
+		if err := f.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
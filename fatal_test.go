@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// recordingFlusher records flushes in a shared call log.
+type recordingFlusher struct {
+	calls *[]string
+}
+
+func (f recordingFlusher) Flush(context.Context) error {
+	*f.calls = append(*f.calls, "flush")
+	return nil
+}
+
+// resetExit restores the exit registries after a test.
+func resetExit(t *testing.T) {
+	t.Helper()
+
+	savedFlushers, savedHooks := flushers, exitHooks
+	flushers, exitHooks = nil, nil
+
+	t.Cleanup(func() {
+		flushers, exitHooks = savedFlushers, savedHooks
+		SetExitFunc(nil)
+	})
+}
+
+func TestFatal(t *testing.T) {
+	tests := []struct {
+		name  string
+		fatal func(l *slog.Logger)
+	}{
+		{
+			name: "fatal",
+			fatal: func(l *slog.Logger) {
+				Fatal(l, "cannot start", "port", 8080)
+			},
+		},
+		{
+			name: "fatal with context",
+			fatal: func(l *slog.Logger) {
+				FatalContext(context.Background(), l, "cannot start", "port", 8080)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetExit(t)
+
+			var (
+				buf   bytes.Buffer
+				calls []string
+				code  = -1
+			)
+
+			l := newLogger(&buf, Options{Format: "json", AddSource: true})
+
+			RegisterFlusher(recordingFlusher{calls: &calls})
+			RegisterExitHook(func() { calls = append(calls, "hook 1") })
+			RegisterExitHook(func() {
+				calls = append(calls, "hook 2")
+				l.Info("closing")
+			})
+			SetExitFunc(func(c int) { code = c })
+
+			tt.fatal(l)
+
+			if code != 1 {
+				t.Errorf("exit code = %d, want 1", code)
+			}
+			if got := strings.Join(calls, ", "); got != "hook 2, hook 1, flush" {
+				t.Errorf("calls = %s, want hooks in reverse order, then flush", got)
+			}
+
+			lines := decodeLines(t, buf.String())
+			if len(lines) != 2 {
+				t.Fatalf("got %d records, want 2", len(lines))
+			}
+			if lines[0]["level"] != "fatal" || lines[0]["msg"] != "cannot start" || lines[0]["port"] != 8080.0 {
+				t.Errorf("out = %v, want fatal record", lines[0])
+			}
+			if src, _ := lines[0]["source"].(string); !strings.Contains(src, "fatal_test.go") {
+				t.Errorf("source = %v, want the caller of Fatal", lines[0]["source"])
+			}
+		})
+	}
+}
+
+func TestPanic(t *testing.T) {
+	tests := []struct {
+		name   string
+		panicF func(l *slog.Logger)
+	}{
+		{
+			name: "panic",
+			panicF: func(l *slog.Logger) {
+				Panic(l, "invariant violated")
+			},
+		},
+		{
+			name: "panic with context",
+			panicF: func(l *slog.Logger) {
+				PanicContext(context.Background(), l, "invariant violated")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetExit(t)
+
+			var (
+				buf   bytes.Buffer
+				calls []string
+			)
+
+			RegisterFlusher(recordingFlusher{calls: &calls})
+			RegisterExitHook(func() { calls = append(calls, "hook") })
+			SetExitFunc(func(int) { t.Error("Panic must not exit") })
+
+			func() {
+				defer func() {
+					if v := recover(); v != "invariant violated" {
+						t.Errorf("recovered %v, want the message", v)
+					}
+				}()
+
+				tt.panicF(newLogger(&buf, Options{Format: "json"}))
+			}()
+
+			if got := strings.Join(calls, ", "); got != "flush" {
+				t.Errorf("calls = %s, want flush only", got)
+			}
+
+			out := decodeLines(t, buf.String())[0]
+			if out["level"] != "panic" || out["msg"] != "invariant violated" {
+				t.Errorf("out = %v, want panic record", out)
+			}
+		})
+	}
+}
+
+func TestLevelNames(t *testing.T) {
+	color.NoColor = true
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "text",
+			opts: Options{Format: "text", Level: "debug"},
+			want: " FATAL ",
+		},
+		{
+			name: "json",
+			opts: Options{Format: "json"},
+			want: `"level":"fatal"`,
+		},
+		{
+			name: "standard replacer",
+			opts: Options{Format: "json", ReplaceStandard: true},
+			want: `"level":"fatal"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			newLogger(&buf, tt.opts).Log(context.Background(), LevelFatal, "test")
+
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
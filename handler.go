@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
@@ -20,18 +22,36 @@ import (
 type Handler struct {
 	slog.Handler
 
-	format string        // format specifies output format: "json" or "text"
-	pretty bool          // pretty enables JSON indentation
-	w      io.Writer     // w is the output destination
-	b      *bytes.Buffer // b is an internal buffer for processing log records
-	m      *sync.Mutex   // m protects concurrent access to the buffer
+	format      string        // format specifies output format: "json" or "text"
+	pretty      bool          // pretty enables JSON indentation
+	stack       string        // stack selects call-stack capture for error records: "caller" or "goroutine"
+	stackDepth  int           // stackDepth limits the number of captured frames
+	stackFilter bool          // stackFilter drops runtime and log/slog frames
+	sourceMode  string        // sourceMode selects how source locations are rendered
+	sourceLink  string        // sourceLink is the OSC 8 URL template for text output, empty if disabled
+	standard    bool          // standard takes level, msg and time from the replaced attributes in JSON format
+	redact      *redactor     // redact removes sensitive data from records, nil if disabled
+	schema      *Schema       // schema restricts the permitted attributes, nil if disabled
+	trusted     bool          // trusted disables control-character escaping in text output
+	limits      limits        // limits bounds the size of records
+	root        slog.Handler  // root is the inner JSON handler without attributes and groups, for context attributes
+	extractors  []Extractor   // extractors derive attributes from the context of every record
+	w           io.Writer     // w is the output destination
+	b           *bytes.Buffer // b is an internal buffer for processing log records
+	m           *sync.Mutex   // m protects concurrent access to the buffer
+	closed      *atomic.Bool  // closed is set by Close and shared with derived handlers
 }
 
 // Handle processes a log record and writes it to the output writer.
 // For JSON format, it creates a structured record with level, message, time, and attributes.
 // For text format, it creates a human-readable colored output.
+// Attributes stored in ctx with WithContextAttrs and those returned by extractors are added at the top level.
+// Writers implementing Flusher, such as BufferedWriter, are flushed after error-level records.
 // This method is thread-safe and handles concurrent logging calls.
 func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	// extractors run outside the lock, so that they may log and do not serialize records
+	ctxAttrs := h.contextAttrs(ctx, r.Level)
+
 	h.m.Lock()
 
 	defer func() {
@@ -39,53 +59,230 @@ func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
 		h.m.Unlock()
 	}()
 
+	if h.closed.Load() {
+		return ErrClosed
+	}
+
 	var (
-		fields = make(map[string]interface{}, r.NumAttrs())
-		out    []byte
+		fields    = make(map[string]interface{}, r.NumAttrs())
+		msg       = r.Message
+		link      string
+		truncated bool
 	)
 
-	if h.format == "json" {
-		fields["level"] = strings.ToLower(r.Level.String())
-		fields["msg"] = r.Message
+	if h.redact != nil {
+		msg, _ = h.redact.redactString(msg)
+	}
+
+	// with standard set, the message is limited as the msg attribute
+	if !h.standard {
+		if v, t := h.limits.limitValue(msg, 0); t {
+			msg, truncated = v.(string), true
+		}
+	}
+
+	// with standard set, level, msg and time come from the ReplaceAttr chain
+	if h.format == "json" && !h.standard {
+		fields["level"] = strings.ToLower(levelName(r.Level))
 		fields["time"] = r.Time.Format(time.DateTime)
-	} else {
-		out = []byte(fmt.Sprintf("%s %s %s ",
-			r.Time.Format(time.DateTime),
-			ParseColor(r.Level.String()),
-			color.CyanString(r.Message),
-		))
 	}
 
-	if err := h.Handler.Handle(ctx, r); err != nil {
-		return err
+	r.PC = skipHelpers(r.PC)
+
+	if limited, ok := h.limits.limitRecord(r); ok {
+		r, truncated = limited, true
+	}
+
+	if h.stack != "" && r.Level >= slog.LevelError && !hasStack(r) {
+		var pc uintptr
+		if h.stack == "caller" {
+			pc = r.PC
+		}
+
+		r.AddAttrs(slog.Any("stack", callerFrames(2, pc, h.stackDepth, h.stackFilter)))
 	}
 
 	attrs := map[string]any{}
-	if err := json.Unmarshal(h.b.Bytes(), &attrs); err != nil {
+
+	// context attributes are decoded first, so that attributes of the record take precedence
+	if len(ctxAttrs) > 0 {
+		cr := slog.NewRecord(r.Time, r.Level, r.Message, 0)
+		cr.AddAttrs(ctxAttrs...)
+
+		if err := h.decode(ctx, h.root, cr, attrs); err != nil {
+			return err
+		}
+	}
+
+	if err := h.decode(ctx, h.Handler, r, attrs); err != nil {
 		return err
 	}
 
+	if h.schema != nil {
+		h.schema.enforce(attrs)
+	}
+
+	if h.redact != nil {
+		h.redact.redactMap(attrs)
+	}
+
+	if h.limits.limitMap(attrs, 1) {
+		truncated = true
+	}
+
+	if h.format == "text" && !h.trusted {
+		sanitizeMap(attrs)
+	}
+
+	if _, ok := attrs[slog.SourceKey]; ok && h.sourceLink != "" && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		src := &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+
+		link = hyperlink(h.sourceLink, f.File, f.Line, sourceValue(h.sourceMode, false, src).String()) + " "
+
+		delete(attrs, slog.SourceKey)
+	}
+
 	for k, v := range attrs {
 		fields[k] = v
 	}
 
-	if h.pretty {
-		if b, err := json.MarshalIndent(fields, "", "  "); err != nil {
+	if h.standard {
+		if s, ok := fields[slog.MessageKey].(string); ok {
+			msg = s
+		}
+	}
+
+	if truncated {
+		fields[TruncatedKey] = true
+	}
+
+	out, err := h.line(r, msg, link, fields)
+	if err != nil {
+		return err
+	}
+
+	if h.limits.line > 0 && len(out) > h.limits.line {
+		if out, err = h.shrinkLine(r, msg, fields); err != nil {
 			return err
-		} else {
-			out = append(out, b...)
+		}
+	}
+
+	h.w.Write(out)
+
+	// buffering writers must not hold back errors
+	if f, ok := h.w.(Flusher); ok && r.Level >= slog.LevelError {
+		return f.Flush(context.WithoutCancel(ctx))
+	}
+
+	return nil
+}
+
+// decode formats the record with the given inner JSON handler and merges the resulting
+// attributes into attrs.
+func (h *Handler) decode(ctx context.Context, handler slog.Handler, r slog.Record, attrs map[string]any) error {
+	defer h.b.Reset()
+
+	if err := h.handle(ctx, handler, resolveRecord(r)); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(h.b.Bytes(), &attrs)
+}
+
+// handle passes the record to an inner JSON handler. If the handler panics, the partial
+// output is replaced with an object holding the panic message, so the record is still written.
+func (h *Handler) handle(ctx context.Context, handler slog.Handler, r slog.Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			h.b.Reset()
+
+			err = json.NewEncoder(h.b).Encode(map[string]string{
+				PanicKey: fmt.Sprintf("!PANIC(Handle): %v", p),
+			})
+		}
+	}()
+
+	return handler.Handle(ctx, r)
+}
+
+// line renders a record with the given message, source hyperlink and fields as an output line.
+func (h *Handler) line(r slog.Record, msg, link string, fields map[string]any) ([]byte, error) {
+	var out []byte
+
+	if h.format == "json" {
+		if _, ok := fields[slog.MessageKey].(string); ok || !h.standard {
+			fields[slog.MessageKey] = msg
 		}
 	} else {
-		if b, err := json.Marshal(fields); err != nil {
-			return err
+		// escape newlines and terminal sequences so that a record is always one line
+		if !h.trusted {
+			msg = escapeControl(msg)
+		}
+
+		out = []byte(fmt.Sprintf("%s %s %s %s",
+			r.Time.Format(time.DateTime),
+			ParseColor(levelName(r.Level)),
+			color.CyanString("%s", msg),
+			link,
+		))
+	}
+
+	b, err := h.marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	out = append(out, b...)
+
+	return append(out, "\n"...), nil
+}
+
+// shrinkLine renders a record exceeding the line size limit with only the fields written
+// by the handler itself, shortening the message until the encoded line fits. The message
+// is cut before escaping, so escape sequences are never split. The line only exceeds the
+// limit if it is too small for the level and time.
+func (h *Handler) shrinkLine(r slog.Record, msg string, fields map[string]any) ([]byte, error) {
+	fields = shrinkFields(fields)
+
+	out, err := h.line(r, msg, "", fields)
+	if err != nil || len(out) <= h.limits.line {
+		return out, err
+	}
+
+	// find the longest prefix of the message that fits; the encoded size grows with its length
+	lo, hi := 0, len(msg)-1
+	out = nil
+
+	for lo <= hi {
+		n := (lo + hi) / 2
+
+		line, err := h.line(r, truncateBytes(msg, n), "", fields)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) <= h.limits.line {
+			out, lo = line, n+1
 		} else {
-			out = append(out, b...)
+			hi = n - 1
 		}
 	}
 
-	h.w.Write(append(out, "\n"...))
+	if out == nil {
+		return h.line(r, truncateBytes(msg, 0), "", fields)
+	}
 
-	return nil
+	return out, nil
+}
+
+// marshal encodes the fields as JSON, indented if pretty output is enabled.
+func (h *Handler) marshal(fields map[string]any) ([]byte, error) {
+	if h.pretty {
+		return json.MarshalIndent(fields, "", "  ")
+	}
+
+	return json.Marshal(fields)
 }
 
 // WithAttrs returns a new Handler with the specified attributes added to all log records.
@@ -97,7 +294,7 @@ func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	}
 
 	h2 := *h
-	h2.Handler = h.Handler.WithAttrs(attrs)
+	h2.Handler = h.Handler.WithAttrs(resolveAttrs(attrs))
 
 	return &h2
 }
@@ -115,6 +312,8 @@ func (h *Handler) WithGroup(name string) slog.Handler {
 // NewHandler creates and initializes a new Handler with the specified output writer and options.
 // If the format option is not "json" or "text", it defaults to "json".
 // The handler uses an internal JSON handler for processing attributes and a buffer for intermediate storage.
+// If the stack option is not "caller" or "goroutine", stack capture is disabled.
+// Source hyperlinks are only enabled for text output to a terminal that supports them.
 func NewHandler(out io.Writer, opts *Options) Handler {
 	b := new(bytes.Buffer)
 
@@ -125,12 +324,39 @@ func NewHandler(out io.Writer, opts *Options) Handler {
 		opts.Format = "json"
 	}
 
+	if !map[string]bool{
+		"caller":    true,
+		"goroutine": true,
+	}[opts.Stack] {
+		opts.Stack = ""
+	}
+
+	sourceLink := ""
+	if opts.Format == "text" && opts.SourceLink != "" && hyperlinksSupported(out) {
+		sourceLink = opts.SourceLink
+	}
+
+	root := slog.NewJSONHandler(b, opts.HandlerOptions)
+
 	return Handler{
-		Handler: slog.NewJSONHandler(b, opts.HandlerOptions),
-		format:  opts.Format,
-		pretty:  opts.Pretty,
-		b:       b,
-		m:       &sync.Mutex{},
-		w:       out,
+		Handler:     root,
+		root:        root,
+		extractors:  append([]Extractor{traceExtractor(opts.TraceSource, opts.TraceFormat, opts.TraceProject)}, opts.Extractors...),
+		format:      opts.Format,
+		pretty:      opts.Pretty,
+		stack:       opts.Stack,
+		stackDepth:  opts.StackDepth,
+		stackFilter: opts.StackFilter,
+		sourceMode:  opts.SourceMode,
+		sourceLink:  sourceLink,
+		standard:    opts.ReplaceStandard && opts.Format == "json",
+		redact:      newRedactor(opts.Redact),
+		schema:      opts.Schema,
+		trusted:     opts.TrustedText,
+		limits:      newLimits(opts),
+		b:           b,
+		m:           &sync.Mutex{},
+		closed:      &atomic.Bool{},
+		w:           out,
 	}
 }
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	helpers     sync.Map     // helpers holds the names of functions marked by Helper
+	helperCount atomic.Int32 // helperCount allows skipping the lookup when no helpers are registered
+)
+
+// Helper marks the calling function as a logging helper, in the spirit of testing.T.Helper.
+// When resolving the source location of a record, the handler skips helper functions
+// and reports the first caller that is not a helper.
+// Calling Helper repeatedly from the same function is cheap.
+func Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	if _, loaded := helpers.LoadOrStore(f.Function, struct{}{}); !loaded {
+		helperCount.Add(1)
+	}
+}
+
+// isHelper reports whether the function containing pc was marked by Helper.
+func isHelper(pc uintptr) bool {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	_, ok := helpers.Load(f.Function)
+
+	return ok
+}
+
+// skipHelpers returns the program counter of the first caller at or above pc that
+// is not a helper function. The stack of the current goroutine is searched for pc,
+// so skipHelpers must be called synchronously from the logging call.
+// If pc cannot be found, it is returned unchanged.
+func skipHelpers(pc uintptr) uintptr {
+	if pc == 0 || helperCount.Load() == 0 || !isHelper(pc) {
+		return pc
+	}
+
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	for i := range pcs {
+		if pcs[i] != pc {
+			continue
+		}
+
+		for _, caller := range pcs[i+1:] {
+			if !isHelper(caller) {
+				return caller
+			}
+		}
+
+		break
+	}
+
+	return pc
+}
+
+// LogDepth emits a log record like l.Log, but reports the source location skip frames
+// above the caller of LogDepth. A skip of 0 reports the caller of LogDepth itself,
+// so a wrapper function passes 1 to attribute the record to its own caller.
+func LogDepth(ctx context.Context, l *slog.Logger, skip int, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	// skip runtime.Callers and LogDepth
+	runtime.Callers(skip+2, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// LogAttrsDepth is like LogDepth but accepts only slog.Attr values, like l.LogAttrs.
+func LogAttrsDepth(ctx context.Context, l *slog.Logger, skip int, level slog.Level, msg string, attrs ...slog.Attr) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	// skip runtime.Callers and LogAttrsDepth
+	runtime.Callers(skip+2, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs...)
+
+	_ = l.Handler().Handle(ctx, r)
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+//go:noinline
+func auditHelper(l *slog.Logger, msg string) {
+	Helper()
+	l.Info(msg)
+}
+
+//go:noinline
+func nestedAuditHelper(l *slog.Logger, msg string) {
+	Helper()
+	auditHelper(l, msg)
+}
+
+//go:noinline
+func depthHelper(l *slog.Logger, msg string) {
+	LogDepth(context.Background(), l, 1, slog.LevelInfo, msg)
+}
+
+//go:noinline
+func attrsDepthHelper(l *slog.Logger, msg string) {
+	LogAttrsDepth(context.Background(), l, 1, slog.LevelInfo, msg, slog.String("key", "value"))
+}
+
+func TestHelper(t *testing.T) {
+	tests := []struct {
+		name    string
+		logFunc func(l *slog.Logger) int
+	}{
+		{
+			name: "helper is skipped",
+			logFunc: func(l *slog.Logger) int {
+				auditHelper(l, "audit")
+				return callerLine() - 1
+			},
+		},
+		{
+			name: "nested helpers are skipped",
+			logFunc: func(l *slog.Logger) int {
+				nestedAuditHelper(l, "audit")
+				return callerLine() - 1
+			},
+		},
+		{
+			name: "explicit skip count",
+			logFunc: func(l *slog.Logger) int {
+				depthHelper(l, "depth")
+				return callerLine() - 1
+			},
+		},
+		{
+			name: "explicit skip count with attrs",
+			logFunc: func(l *slog.Logger) int {
+				attrsDepthHelper(l, "depth")
+				return callerLine() - 1
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := Options{
+				HandlerOptions: &slog.HandlerOptions{AddSource: true},
+				Format:         "json",
+			}
+
+			handler := NewHandler(&buf, &opts)
+			want := tt.logFunc(slog.New(&handler))
+
+			var out struct {
+				Source slog.Source `json:"source"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			if out.Source.Line != want {
+				t.Errorf("source line = %d (%s), want %d", out.Source.Line, out.Source.Function, want)
+			}
+		})
+	}
+}
+
+func TestLogDepth_Disabled(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{
+		HandlerOptions: &slog.HandlerOptions{Level: slog.LevelWarn},
+		Format:         "json",
+	}
+
+	handler := NewHandler(&buf, &opts)
+	LogDepth(nil, slog.New(&handler), 0, slog.LevelInfo, "filtered")
+
+	if buf.Len() != 0 {
+		t.Errorf("disabled level should not be logged: %q", buf.String())
+	}
+}
+
+// callerLine returns the line number of its caller.
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// hyperlink wraps text in an OSC 8 escape sequence pointing to the URL built from
+// template. The placeholders {path} and {line} are replaced with the absolute file
+// path and the line number.
+func hyperlink(template, path string, line int, text string) string {
+	link := strings.NewReplacer(
+		"{path}", (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath(),
+		"{line}", strconv.Itoa(line),
+	).Replace(template)
+
+	return "\x1b]8;;" + link + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// hyperlinksSupported reports whether w is a terminal known to render OSC 8 hyperlinks.
+// Setting FORCE_HYPERLINK=1 enables hyperlinks regardless of the output, FORCE_HYPERLINK=0
+// disables them.
+func hyperlinksSupported(w io.Writer) bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		enabled, _ := strconv.ParseBool(force)
+		return enabled
+	}
+
+	f, ok := w.(*os.File)
+	if !ok || !isatty.IsTerminal(f.Fd()) {
+		return false
+	}
+
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "vscode", "WezTerm", "Hyper", "ghostty":
+		return true
+	}
+
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KONSOLE_VERSION") != "" {
+		return true
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+
+	term := os.Getenv("TERM")
+
+	return strings.Contains(term, "kitty") || strings.Contains(term, "alacritty")
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHyperlink(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		line     int
+		want     string
+	}{
+		{
+			name:     "vscode template",
+			template: "vscode://file/{path}:{line}",
+			path:     "/src/app/main.go",
+			line:     12,
+			want:     "\x1b]8;;vscode://file//src/app/main.go:12\x1b\\main.go:12\x1b]8;;\x1b\\",
+		},
+		{
+			name:     "file template escapes path",
+			template: "file://{path}",
+			path:     "/src/my app/main.go",
+			line:     3,
+			want:     "\x1b]8;;file:///src/my%20app/main.go\x1b\\main.go:12\x1b]8;;\x1b\\",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hyperlink(tt.template, tt.path, tt.line, "main.go:12")
+			if got != tt.want {
+				t.Errorf("hyperlink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHyperlinksSupported(t *testing.T) {
+	var buf bytes.Buffer
+
+	t.Setenv("TERM_PROGRAM", "vscode")
+	if hyperlinksSupported(&buf) {
+		t.Error("hyperlinks should be disabled for non-terminal writers")
+	}
+
+	t.Setenv("FORCE_HYPERLINK", "1")
+	if !hyperlinksSupported(&buf) {
+		t.Error("FORCE_HYPERLINK=1 should enable hyperlinks")
+	}
+
+	t.Setenv("FORCE_HYPERLINK", "0")
+	if hyperlinksSupported(&buf) {
+		t.Error("FORCE_HYPERLINK=0 should disable hyperlinks")
+	}
+}
+
+func TestHandler_SourceLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		force  string
+		format string
+		want   bool
+	}{
+		{
+			name:   "text output to capable terminal",
+			force:  "1",
+			format: "text",
+			want:   true,
+		},
+		{
+			name:   "text output without capable terminal",
+			force:  "0",
+			format: "text",
+			want:   false,
+		},
+		{
+			name:   "json output is never linked",
+			force:  "1",
+			format: "json",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FORCE_HYPERLINK", tt.force)
+
+			var buf bytes.Buffer
+			opts := Options{
+				HandlerOptions: &slog.HandlerOptions{AddSource: true},
+				Format:         tt.format,
+				SourceLink:     "vscode://file/{path}:{line}",
+			}
+
+			handler := NewHandler(&buf, &opts)
+			slog.New(&handler).Info("linked")
+
+			got := strings.Contains(buf.String(), "\x1b]8;;vscode://file/")
+			if got != tt.want {
+				t.Errorf("hyperlink in output = %v, want %v: %q", got, tt.want, buf.String())
+			}
+			if got && !strings.Contains(buf.String(), "hyperlink_test.go") {
+				t.Errorf("hyperlink should point to the call site: %q", buf.String())
+			}
+		})
+	}
+}
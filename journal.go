@@ -0,0 +1,319 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultJournalSocket is the socket of the native protocol of systemd-journald.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// maxJournalFieldName is the maximum length of journal field names accepted by journald.
+const maxJournalFieldName = 64
+
+// journalReserved holds the fields set by JournalWriter itself. Attributes converting to
+// one of these names are prefixed with "ATTR_", so that they cannot forge them.
+var journalReserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+// JournalOptions configures NewJournalWriter and NewJournalHandler.
+type JournalOptions struct {
+	Socket     string // Socket is the path of the journald socket (default DefaultJournalSocket)
+	Identifier string // Identifier is the SYSLOG_IDENTIFIER field (default the executable name)
+}
+
+// JournalWriter sends the JSON records written by a Handler to systemd-journald using its
+// native protocol. Each Write must contain a single record.
+//
+// The message becomes MESSAGE, the level becomes PRIORITY with the severities of SyslogWriter,
+// and a structured source attribute becomes CODE_FILE, CODE_LINE and CODE_FUNC. Other attributes
+// become fields named in UPPER_SNAKE case, with groups joined by "_", e.g. "http.statusCode"
+// becomes HTTP_STATUS_CODE. Attributes named like the fields above are prefixed with "ATTR_",
+// e.g. "message" becomes ATTR_MESSAGE. Entries too large for a datagram are passed in a sealed
+// memfd. If a write fails, the connection is re-established and the write is retried once.
+type JournalWriter struct {
+	opts JournalOptions
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournalWriter connects to the journald socket described by opts.
+func NewJournalWriter(opts JournalOptions) (*JournalWriter, error) {
+	if opts.Socket == "" {
+		opts.Socket = DefaultJournalSocket
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+
+	w := &JournalWriter{opts: opts}
+
+	conn, err := w.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	w.conn = conn
+
+	return w, nil
+}
+
+// NewJournalHandler returns a handler sending records to journald as described by jopts.
+// Records are processed like those of NewLogger with opts, including redaction, limits
+// and context attributes. The source is always added, with the full path unless opts has
+// another SourceMode. Closing the handler closes the connection.
+func NewJournalHandler(opts Options, jopts JournalOptions) (CloseHandler, error) {
+	if opts.Null {
+		return NewNullHandler(), nil
+	}
+
+	w, err := NewJournalWriter(jopts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Format = "json"
+	opts.Pretty = false
+	opts.AddSource = true
+	opts.SourceStruct = true
+	if opts.SourceMode == "" {
+		opts.SourceMode = "full"
+	}
+
+	return newHandler(w, opts).(CloseHandler), nil
+}
+
+// Write sends the record in p, reconnecting once if the connection fails.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	entry := w.entry(p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if err := w.send(entry); err == nil {
+			return len(p), nil
+		}
+
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	conn, err := w.dial()
+	if err != nil {
+		return 0, err
+	}
+
+	w.conn = conn
+
+	if err := w.send(entry); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to journald.
+func (w *JournalWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
+
+// dial connects to the journald socket.
+func (w *JournalWriter) dial() (*net.UnixConn, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.opts.Socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+
+	return conn, nil
+}
+
+// send writes an entry as a datagram, or in a memfd if it is too large.
+func (w *JournalWriter) send(entry []byte) error {
+	_, err := w.conn.Write(entry)
+	if err != nil && journalEntryTooLarge(err) {
+		return sendJournalFile(w.conn, entry)
+	}
+
+	return err
+}
+
+// entry converts a JSON record into a journal entry. Writes that are not JSON
+// objects are sent as the message at info level.
+func (w *JournalWriter) entry(p []byte) []byte {
+	line := bytes.TrimSpace(p)
+
+	var attrs map[string]any
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&attrs); err != nil {
+		attrs = map[string]any{slog.MessageKey: string(line)}
+	}
+
+	level, _ := attrs[slog.LevelKey].(string)
+	msg, _ := attrs[slog.MessageKey].(string)
+	source := attrs[slog.SourceKey]
+
+	for _, k := range []string{slog.LevelKey, slog.MessageKey, slog.TimeKey, slog.SourceKey} {
+		delete(attrs, k)
+	}
+
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", msg)
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(syslogSeverity(level)))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", w.opts.Identifier)
+
+	switch s := source.(type) {
+	case map[string]any:
+		if file, ok := s["file"].(string); ok {
+			writeJournalField(&b, "CODE_FILE", file)
+		}
+		if line, ok := s["line"].(json.Number); ok {
+			writeJournalField(&b, "CODE_LINE", line.String())
+		}
+		if function, ok := s["function"].(string); ok && function != "" {
+			writeJournalField(&b, "CODE_FUNC", function)
+		}
+	case string:
+		if file, line, ok := strings.Cut(s, ":"); ok {
+			writeJournalField(&b, "CODE_FILE", file)
+			writeJournalField(&b, "CODE_LINE", line)
+		}
+	}
+
+	fields := map[string]string{}
+	flattenJournalFields("", attrs, fields)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		writeJournalField(&b, name, fields[name])
+	}
+
+	return b.Bytes()
+}
+
+// flattenJournalFields adds the values of m to fields under journal field names,
+// joining the keys of nested groups with "_".
+func flattenJournalFields(prefix string, m map[string]any, fields map[string]string) {
+	for k, v := range m {
+		if g, ok := v.(map[string]any); ok {
+			flattenJournalFields(prefix+k+"_", g, fields)
+			continue
+		}
+
+		name := journalFieldName(prefix + k)
+		if name == "" {
+			continue
+		}
+
+		if journalReserved[name] {
+			name = "ATTR_" + name
+		}
+
+		switch v := v.(type) {
+		case string:
+			fields[name] = v
+		case nil:
+			fields[name] = ""
+		case json.Number:
+			fields[name] = v.String()
+		case bool:
+			fields[name] = strconv.FormatBool(v)
+		default:
+			j, _ := json.Marshal(v)
+			fields[name] = string(j)
+		}
+	}
+}
+
+// journalFieldName converts an attribute key to a journal field name: upper case letters,
+// digits and single underscores, with words of camel case keys separated, at most 64
+// characters. Leading underscores are removed, as they mark fields set by journald itself,
+// and names starting with a digit are prefixed with "X_".
+func journalFieldName(key string) string {
+	var b strings.Builder
+
+	rs := []rune(key)
+	for i, c := range rs {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			// a word starts at an upper case letter after a lower case letter or digit,
+			// or at the last upper case letter of an acronym followed by a lower case letter
+			if i > 0 && (isLowerOrDigit(rs[i-1]) || i+1 < len(rs) && rs[i+1] >= 'a' && rs[i+1] <= 'z') {
+				b.WriteByte('_')
+			}
+			b.WriteRune(c)
+		case c >= 'a' && c <= 'z':
+			b.WriteRune(c - 'a' + 'A')
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	// collapse and trim underscores
+	name := strings.Join(strings.FieldsFunc(b.String(), func(c rune) bool { return c == '_' }), "_")
+
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "X_" + name
+	}
+
+	if len(name) > maxJournalFieldName {
+		name = strings.TrimRight(name[:maxJournalFieldName], "_")
+	}
+
+	return name
+}
+
+// isLowerOrDigit reports whether c is an ASCII lower case letter or digit.
+func isLowerOrDigit(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// writeJournalField appends a field in the native protocol: "NAME=value\n", or for values
+// containing newlines the name, a newline, the value length as a little endian uint64,
+// the value and a newline.
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name + "=" + value + "\n")
+		return
+	}
+
+	b.WriteString(name + "\n")
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value + "\n")
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// journalEntryTooLarge reports whether err means that an entry does not fit into a datagram.
+func journalEntryTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendJournalFile passes entry to journald in a sealed memfd, as journald accepts
+// for entries too large for a datagram.
+func sendJournalFile(conn *net.UnixConn, entry []byte) error {
+	fd, err := unix.MemfdCreate("journal-entry", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	defer unix.Close(fd)
+
+	for b := entry; len(b) > 0; {
+		n, err := unix.Write(fd, b)
+		if err != nil {
+			return fmt.Errorf("journal: %w", err)
+		}
+
+		b = b[n:]
+	}
+
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, seals); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+
+	// WriteMsgUnix refuses connected datagram sockets, so the descriptor is sent directly
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+
+	var sendErr error
+	if err := raw.Write(func(s uintptr) bool {
+		sendErr = unix.Sendmsg(int(s), nil, unix.UnixRights(fd), nil, 0)
+		return sendErr != unix.EAGAIN
+	}); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	if sendErr != nil {
+		return fmt.Errorf("journal: %w", sendErr)
+	}
+
+	return nil
+}
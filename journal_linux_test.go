@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// journalSocket listens on a unixgram socket standing in for DefaultJournalSocket.
+func journalSocket(t *testing.T, path string) *net.UnixConn {
+	t.Helper()
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+// readJournalEntry reads an entry from conn, from the datagram or from a passed file.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 256*1024)
+	oob := make([]byte, syscall.CmsgSpace(4))
+
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("ReadMsgUnix() error = %v", err)
+	}
+
+	if oobn == 0 {
+		return parseJournalEntry(t, buf[:n])
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parseJournalEntry(t, entry)
+}
+
+func TestNewJournalHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+	socket := journalSocket(t, path)
+	defer socket.Close()
+
+	handler, err := NewJournalHandler(Options{Level: "debug"}, JournalOptions{Socket: path, Identifier: "app"})
+	if err != nil {
+		t.Fatalf("NewJournalHandler() error = %v", err)
+	}
+	defer handler.Close(context.Background())
+
+	logger := slog.New(handler).With("requestID", "r-1")
+
+	tests := []struct {
+		name     string
+		log      func()
+		priority string
+		message  string
+	}{
+		{"debug", func() { logger.Debug("probe") }, "7", "probe"},
+		{"info", func() { logger.Info("started") }, "6", "started"},
+		{"warn", func() { logger.Warn("slow") }, "4", "slow"},
+		{"error", func() { logger.Error("failed") }, "3", "failed"},
+		{"fatal", func() { logger.Log(context.Background(), LevelFatal, "crashed") }, "1", "crashed"},
+		{"reserved fields", func() { logger.Info("real", "message", "forged", "priority", 0) }, "6", "real"},
+		{"large", func() { logger.Info("large", "payload", strings.Repeat("x", 512*1024)) }, "6", "large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log()
+
+			got := readJournalEntry(t, socket)
+
+			if got["PRIORITY"] != tt.priority || got["MESSAGE"] != tt.message {
+				t.Errorf("PRIORITY, MESSAGE = %q, %q, want %q, %q", got["PRIORITY"], got["MESSAGE"], tt.priority, tt.message)
+			}
+			if got["REQUEST_ID"] != "r-1" || got["SYSLOG_IDENTIFIER"] != "app" {
+				t.Errorf("REQUEST_ID, SYSLOG_IDENTIFIER = %q, %q, want r-1, app", got["REQUEST_ID"], got["SYSLOG_IDENTIFIER"])
+			}
+			if !strings.HasSuffix(got["CODE_FILE"], "/journal_linux_test.go") || got["CODE_LINE"] == "" ||
+				!strings.Contains(got["CODE_FUNC"], ".TestNewJournalHandler.") {
+				t.Errorf("CODE_FILE, CODE_LINE, CODE_FUNC = %q, %q, %q, want this test", got["CODE_FILE"], got["CODE_LINE"], got["CODE_FUNC"])
+			}
+			if tt.name == "reserved fields" && (got["ATTR_MESSAGE"] != "forged" || got["ATTR_PRIORITY"] != "0") {
+				t.Errorf("ATTR_MESSAGE, ATTR_PRIORITY = %q, %q, want the attributes prefixed", got["ATTR_MESSAGE"], got["ATTR_PRIORITY"])
+			}
+			if tt.name == "large" && len(got["PAYLOAD"]) != 512*1024 {
+				t.Errorf("PAYLOAD has %d bytes, want %d", len(got["PAYLOAD"]), 512*1024)
+			}
+		})
+	}
+}
+
+func TestJournalWriter_reconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+	socket := journalSocket(t, path)
+
+	w, err := NewJournalWriter(JournalOptions{Socket: path})
+	if err != nil {
+		t.Fatalf("NewJournalWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte(`{"level":"info","msg":"before"}`)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := readJournalEntry(t, socket); got["MESSAGE"] != "before" {
+		t.Errorf("MESSAGE = %q, want before", got["MESSAGE"])
+	}
+
+	// restart journald
+	socket.Close()
+	os.Remove(path)
+	socket = journalSocket(t, path)
+	defer socket.Close()
+
+	if _, err := w.Write([]byte(`{"level":"info","msg":"after"}`)); err != nil {
+		t.Fatalf("Write() after restart error = %v", err)
+	}
+	if got := readJournalEntry(t, socket); got["MESSAGE"] != "after" {
+		t.Errorf("MESSAGE = %q, want after", got["MESSAGE"])
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+// journalEntryTooLarge reports whether err means that an entry does not fit into a datagram.
+// Entries are never passed in files outside of Linux.
+func journalEntryTooLarge(error) bool {
+	return false
+}
+
+// sendJournalFile is not supported outside of Linux, as journald runs on Linux only.
+func sendJournalFile(*net.UnixConn, []byte) error {
+	return errors.New("journal: large entries are only supported on linux")
+}
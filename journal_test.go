@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"user", "USER"},
+		{"request_id", "REQUEST_ID"},
+		{"requestID", "REQUEST_ID"},
+		{"statusCode", "STATUS_CODE"},
+		{"HTTPServer", "HTTP_SERVER"},
+		{"http.status", "HTTP_STATUS"},
+		{"__cursor", "CURSOR"},
+		{"a--b", "A_B"},
+		{"2fa", "X_2FA"},
+		{"ключ", ""},
+		{"a_very_long_attribute_name_that_exceeds_the_journal_limit_of_64_chars", "A_VERY_LONG_ATTRIBUTE_NAME_THAT_EXCEEDS_THE_JOURNAL_LIMIT_OF_64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := journalFieldName(tt.key); got != tt.want {
+				t.Errorf("journalFieldName(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalWriter_entry(t *testing.T) {
+	w := &JournalWriter{opts: JournalOptions{Identifier: "app"}}
+
+	record := `{"time":"2024-01-02 03:04:05","level":"warn","msg":"disk low","source":{"file":"/src/main.go","line":42,"function":"main.run"},` +
+		`"freeBytes":12,"http":{"statusCode":507},"ok":false,"tags":["a","b"],"trace":"line 1\nline 2",` +
+		`"message":"forged","priority":0,"code":{"file":"forged.go"}}` + "\n"
+
+	got := parseJournalEntry(t, w.entry([]byte(record)))
+
+	want := map[string]string{
+		"MESSAGE":           "disk low",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "app",
+		"CODE_FILE":         "/src/main.go",
+		"CODE_LINE":         "42",
+		"CODE_FUNC":         "main.run",
+		"FREE_BYTES":        "12",
+		"HTTP_STATUS_CODE":  "507",
+		"OK":                "false",
+		"TAGS":              `["a","b"]`,
+		"TRACE":             "line 1\nline 2",
+		"ATTR_MESSAGE":      "forged",
+		"ATTR_PRIORITY":     "0",
+		"ATTR_CODE_FILE":    "forged.go",
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("entry has %d fields, want %d: %q", len(got), len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+// parseJournalEntry decodes an entry in the native journal protocol.
+func parseJournalEntry(t *testing.T, entry []byte) map[string]string {
+	t.Helper()
+
+	fields := map[string]string{}
+
+	for len(entry) > 0 {
+		i := bytes.IndexAny(entry, "=\n")
+		if i < 0 {
+			t.Fatalf("malformed entry at %q", entry)
+		}
+
+		name := string(entry[:i])
+
+		if entry[i] == '=' {
+			end := bytes.IndexByte(entry, '\n')
+			fields[name] = string(entry[i+1 : end])
+			entry = entry[end+1:]
+
+			continue
+		}
+
+		entry = entry[i+1:]
+		n := binary.LittleEndian.Uint64(entry)
+		fields[name] = string(entry[8 : 8+n])
+
+		if entry[8+n] != '\n' {
+			t.Fatalf("field %s is not terminated by a newline", name)
+		}
+
+		entry = entry[8+n+1:]
+	}
+
+	return fields
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"syscall"
+)
+
+// ErrClosed is returned by Handler.Handle after the handler has been closed.
+var ErrClosed = errors.New("logger: handler closed")
+
+// CloseHandler is a handler owning its output, such as a network connection, which is
+// released by Close. Handler and NullHandler implement it.
+type CloseHandler interface {
+	slog.Handler
+	Flusher
+
+	Close(ctx context.Context) error
+}
+
+// Flush flushes the output writer of h if it buffers data, that is if it implements
+// Flusher, has a Flush() error method like bufio.Writer, or a Sync() error method like os.File.
+// Handlers derived with WithAttrs and WithGroup share the writer, so flushing any of them
+// flushes all. Flush returns ctx.Err() if ctx is done before the writer is flushed.
+func (h *Handler) Flush(ctx context.Context) error {
+	if h.closed.Load() {
+		return nil
+	}
+
+	return h.sync(ctx, false)
+}
+
+// Close flushes and closes the output writer of h, unless it is os.Stdout or os.Stderr.
+// Handlers derived with WithAttrs and WithGroup share the writer and are closed as well:
+// further records are rejected with ErrClosed. Closing a closed handler does nothing.
+func (h *Handler) Close(ctx context.Context) error {
+	if h.closed.Swap(true) {
+		return nil
+	}
+
+	return h.sync(ctx, true)
+}
+
+// sync flushes, and closes if close is set, the writer once pending records are written.
+func (h *Handler) sync(ctx context.Context, close bool) error {
+	done := make(chan error, 1)
+
+	go func() {
+		h.m.Lock()
+		defer h.m.Unlock()
+
+		err := flushWriter(ctx, h.w)
+		if close {
+			err = errors.Join(err, closeWriter(h.w))
+		}
+
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushWriter flushes w if it buffers data. Sync errors of files that cannot be synced,
+// such as terminals and pipes, are ignored.
+func flushWriter(ctx context.Context, w io.Writer) error {
+	switch w := w.(type) {
+	case Flusher:
+		return w.Flush(ctx)
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		if err := w.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeWriter closes w if it is an io.Closer other than the standard streams.
+func closeWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
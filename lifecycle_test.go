@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lifecycleWriter records Flush and Close calls; block makes Flush wait until released.
+type lifecycleWriter struct {
+	bytes.Buffer
+
+	flushes int
+	closes  int
+	block   chan struct{}
+}
+
+func (w *lifecycleWriter) Flush(ctx context.Context) error {
+	if w.block != nil {
+		<-w.block
+	}
+
+	w.flushes++
+
+	return nil
+}
+
+func (w *lifecycleWriter) Close() error {
+	w.closes++
+	return nil
+}
+
+func TestHandler_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+
+	opts := Options{Format: "json"}
+	handler := NewHandler(bw, &opts)
+
+	derived := handler.WithAttrs([]slog.Attr{slog.String("a", "b")}).WithGroup("g")
+	slog.New(derived).Info("buffered")
+
+	if buf.Len() != 0 {
+		t.Fatalf("output = %q, want nothing before Flush", buf.String())
+	}
+
+	if err := derived.(*Handler).Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"buffered"`) {
+		t.Errorf("output = %q, want the record after Flush", buf.String())
+	}
+}
+
+func TestHandler_Close(t *testing.T) {
+	w := &lifecycleWriter{}
+
+	opts := Options{Format: "json"}
+	handler := NewHandler(w, &opts)
+	derived := handler.WithGroup("g").(*Handler)
+
+	if err := derived.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if w.flushes != 1 || w.closes != 1 {
+		t.Errorf("flushes, closes = %d, %d, want 1, 1", w.flushes, w.closes)
+	}
+
+	// the root handler shares the writer and is closed as well
+	err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0))
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Handle() error = %v, want ErrClosed", err)
+	}
+
+	if err := handler.Close(context.Background()); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if err := handler.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() after Close error = %v", err)
+	}
+	if w.flushes != 1 || w.closes != 1 {
+		t.Errorf("flushes, closes = %d, %d, want no further calls", w.flushes, w.closes)
+	}
+}
+
+func TestHandler_FlushContext(t *testing.T) {
+	w := &lifecycleWriter{block: make(chan struct{})}
+	defer close(w.block)
+
+	opts := Options{Format: "json"}
+	handler := NewHandler(w, &opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := handler.Flush(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Flush() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestCloseWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		w          *lifecycleWriter
+		std        *os.File
+		wantCloses int
+	}{
+		{
+			name:       "closer",
+			w:          &lifecycleWriter{},
+			wantCloses: 1,
+		},
+		{
+			name: "stdout",
+			std:  os.Stdout,
+		},
+		{
+			name: "stderr",
+			std:  os.Stderr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.std != nil {
+				if err := closeWriter(tt.std); err != nil {
+					t.Errorf("closeWriter() error = %v", err)
+				}
+				if _, err := tt.std.Stat(); err != nil {
+					t.Errorf("%s was closed: %v", tt.name, err)
+				}
+				return
+			}
+
+			if err := closeWriter(tt.w); err != nil {
+				t.Errorf("closeWriter() error = %v", err)
+			}
+			if tt.w.closes != tt.wantCloses {
+				t.Errorf("closes = %d, want %d", tt.w.closes, tt.wantCloses)
+			}
+		})
+	}
+}
+
+func TestNullHandler_Lifecycle(t *testing.T) {
+	h := NewNullHandler()
+
+	if err := h.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+	if err := h.Close(context.Background()); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestSetGlobalLogger_Shutdown(t *testing.T) {
+	originalLogger := slog.Default()
+	originalWriter, originalFlags := log.Writer(), log.Flags()
+	defer func() {
+		slog.SetDefault(originalLogger)
+		log.SetOutput(originalWriter)
+		log.SetFlags(originalFlags)
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	shutdown := SetGlobalLogger(Options{Format: "json"})
+
+	// an incomplete line written to the log package is flushed on shutdown
+	log.Writer().Write([]byte("partial line"))
+
+	err := shutdown(context.Background())
+	slog.Info("after shutdown")
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 1 || lines[0]["msg"] != "partial line" {
+		t.Errorf("lines = %v, want only the flushed partial line", lines)
+	}
+}
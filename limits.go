@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"log/slog"
+	"unicode/utf8"
+)
+
+// TruncatedKey is set to true on records that were shortened by one of the size limits.
+const TruncatedKey = "_truncated"
+
+// limits bounds the size of log records. Zero values disable the corresponding limit.
+type limits struct {
+	str      int // str is the maximum number of characters in a string value
+	attrs    int // attrs is the maximum number of attributes per record
+	depth    int // depth is the maximum nesting depth of groups and maps
+	elements int // elements is the maximum number of slice elements
+	line     int // line is the maximum size of an output line in bytes
+}
+
+// newLimits returns the size limits configured in opts.
+func newLimits(opts *Options) limits {
+	return limits{
+		str:      opts.MaxStringLength,
+		attrs:    opts.MaxAttrs,
+		depth:    opts.MaxDepth,
+		elements: opts.MaxElements,
+		line:     opts.MaxLineSize,
+	}
+}
+
+// limitRecord keeps at most the first limits.attrs attributes of r.
+// The second return value reports whether any attributes were removed.
+func (l limits) limitRecord(r slog.Record) (slog.Record, bool) {
+	if l.attrs < 1 || r.NumAttrs() <= l.attrs {
+		return r, false
+	}
+
+	limited := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	n := l.attrs
+	r.Attrs(func(a slog.Attr) bool {
+		limited.AddAttrs(a)
+		n--
+
+		return n > 0
+	})
+
+	return limited, true
+}
+
+// limitMap applies the string, depth and element limits to decoded attributes in place,
+// starting at nesting depth depth. Reports whether anything was truncated.
+func (l limits) limitMap(m map[string]any, depth int) bool {
+	truncated := false
+
+	for k, v := range m {
+		v, t := l.limitValue(v, depth)
+		m[k] = v
+		truncated = truncated || t
+	}
+
+	return truncated
+}
+
+// limitValue applies the limits to a decoded JSON value at the given nesting depth.
+// Groups, maps and slices nested deeper than the depth limit are replaced with "…".
+func (l limits) limitValue(v any, depth int) (any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		if l.depth > 0 && depth >= l.depth {
+			return "…", true
+		}
+
+		return v, l.limitMap(v, depth+1)
+	case []any:
+		if l.depth > 0 && depth >= l.depth {
+			return "…", true
+		}
+
+		truncated := false
+		if l.elements > 0 && len(v) > l.elements {
+			v, truncated = v[:l.elements], true
+		}
+
+		for i := range v {
+			var t bool
+			v[i], t = l.limitValue(v[i], depth+1)
+			truncated = truncated || t
+		}
+
+		return v, truncated
+	case string:
+		// markers set by the handler itself are never shortened
+		if v == SchemaMarker {
+			return v, false
+		}
+
+		if l.str > 0 && utf8.RuneCountInString(v) > l.str {
+			return truncate(v, l.str), true
+		}
+	}
+
+	return v, false
+}
+
+// shrinkFields keeps only the fields written by the handler itself and marks the
+// record as truncated. It is used when a record exceeds the line size limit.
+func shrinkFields(fields map[string]any) map[string]any {
+	shrunk := map[string]any{TruncatedKey: true}
+
+	for _, key := range []string{slog.LevelKey, slog.MessageKey, slog.TimeKey} {
+		if v, ok := fields[key]; ok {
+			shrunk[key] = v
+		}
+	}
+
+	return shrunk
+}
+
+// truncateBytes shortens s to at most n bytes without splitting a UTF-8 sequence,
+// appending an ellipsis if anything was cut off.
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + "…"
+}
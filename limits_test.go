@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Limits(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		logFunc       func(logger *slog.Logger)
+		wantTruncated bool
+		validate      func(t *testing.T, out map[string]any, raw string)
+	}{
+		{
+			name: "no limits",
+			opts: Options{Format: "json"},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "key", strings.Repeat("x", 1000))
+			},
+		},
+		{
+			name: "string length",
+			opts: Options{Format: "json", MaxStringLength: 5},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "key", "abcdefgh", "raw;raw", "abcdefgh", "short", "abc")
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				if out["key"] != "abcde…" {
+					t.Errorf("key = %v, want abcde…", out["key"])
+				}
+				if out["raw"] != `"abcd…` {
+					t.Errorf("raw = %v, want %q", out["raw"], `"abcd…`)
+				}
+				if out["short"] != "abc" {
+					t.Errorf("short = %v, want abc", out["short"])
+				}
+			},
+		},
+		{
+			name: "message length",
+			opts: Options{Format: "json", MaxStringLength: 5},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("abcdefgh")
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				if out["msg"] != "abcde…" {
+					t.Errorf("msg = %v, want abcde…", out["msg"])
+				}
+			},
+		},
+		{
+			name: "message length with standard keys replaced",
+			opts: Options{Format: "json", MaxStringLength: 5, ReplaceStandard: true},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("abcdefgh")
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				if out["msg"] != "abcde…" {
+					t.Errorf("msg = %v, want abcde…", out["msg"])
+				}
+			},
+		},
+		{
+			name: "attribute count",
+			opts: Options{Format: "json", MaxAttrs: 2},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "a", 1, "b", 2, "c", 3)
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				if _, ok := out["c"]; ok {
+					t.Error("c should be dropped")
+				}
+				if out["a"] != 1.0 || out["b"] != 2.0 {
+					t.Errorf("a, b = %v, %v, want 1, 2", out["a"], out["b"])
+				}
+			},
+		},
+		{
+			name: "nesting depth",
+			opts: Options{Format: "json", MaxDepth: 2},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", slog.Group("a", slog.Group("b", "c", 1)), "m", map[string]any{"k": 1})
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				a, _ := out["a"].(map[string]any)
+				if a["b"] != "…" {
+					t.Errorf("a = %v, want b replaced", out["a"])
+				}
+				if m, _ := out["m"].(map[string]any); m["k"] != 1.0 {
+					t.Errorf("m = %v, want k kept", out["m"])
+				}
+			},
+		},
+		{
+			name: "slice elements",
+			opts: Options{Format: "json", MaxElements: 3},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "list", []int{1, 2, 3, 4, 5})
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				if list, _ := out["list"].([]any); len(list) != 3 {
+					t.Errorf("list = %v, want 3 elements", out["list"])
+				}
+			},
+		},
+		{
+			name: "line size",
+			opts: Options{Format: "json", MaxLineSize: 200},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "payload", strings.Repeat("x", 1000))
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, raw string) {
+				if len(raw) > 200 {
+					t.Errorf("line size = %d, want <= 200", len(raw))
+				}
+				if out["msg"] != "test" {
+					t.Errorf("msg = %v, want test", out["msg"])
+				}
+				if _, ok := out["payload"]; ok {
+					t.Error("payload should be dropped")
+				}
+			},
+		},
+		{
+			name: "line size with long message",
+			opts: Options{Format: "json", MaxLineSize: 200},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info(strings.Repeat("ü", 500))
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, _ map[string]any, raw string) {
+				if len(raw) > 200 {
+					t.Errorf("line size = %d, want <= 200", len(raw))
+				}
+			},
+		},
+		{
+			name: "line size with escaped message",
+			opts: Options{Format: "json", MaxLineSize: 100},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info(strings.Repeat("\x01", 50))
+			},
+			wantTruncated: true,
+			validate: func(t *testing.T, out map[string]any, raw string) {
+				if len(raw) > 100 {
+					t.Errorf("line size = %d, want <= 100", len(raw))
+				}
+				if msg, _ := out["msg"].(string); !strings.HasPrefix(msg, "\x01") || !strings.HasSuffix(msg, "…") {
+					t.Errorf("msg = %q, want a shortened message", msg)
+				}
+			},
+		},
+		{
+			name: "schema marker is not shortened",
+			opts: Options{Format: "json", MaxStringLength: 3, Schema: &Schema{Fields: map[string]slog.Kind{}}},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("ok", "pan", "4111111111111111")
+			},
+			validate: func(t *testing.T, out map[string]any, _ string) {
+				if out["pan"] != SchemaMarker {
+					t.Errorf("pan = %v, want %s", out["pan"], SchemaMarker)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.logFunc(newLogger(&buf, tt.opts))
+
+			out := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			if got := out[TruncatedKey] == true; got != tt.wantTruncated {
+				t.Errorf("%s = %v, want %v", TruncatedKey, got, tt.wantTruncated)
+			}
+
+			if tt.validate != nil {
+				tt.validate(t, out, buf.String())
+			}
+		})
+	}
+}
+
+func TestHandler_LineSizeText(t *testing.T) {
+	tests := []struct {
+		name    string
+		logFunc func(logger *slog.Logger)
+	}{
+		{"escaped message", func(logger *slog.Logger) { logger.Info(strings.Repeat("\x01", 50)) }},
+		{"long attributes", func(logger *slog.Logger) { logger.Info("test", "payload", strings.Repeat("x", 1000)) }},
+		{"newlines", func(logger *slog.Logger) { logger.Info(strings.Repeat("a\nb", 100), "k", "v") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.logFunc(newLogger(&buf, Options{Format: "text", MaxLineSize: 100}))
+
+			if got := buf.String(); len(got) > 100 || strings.Count(got, "\n") != 1 {
+				t.Errorf("line = %q (%d bytes), want one line of at most 100 bytes", got, len(got))
+			}
+			if !strings.Contains(buf.String(), `"_truncated":true`) {
+				t.Errorf("line = %q, want the truncation marker", buf.String())
+			}
+		})
+	}
+}
+
+func TestHandler_MessageLengthText(t *testing.T) {
+	var buf bytes.Buffer
+
+	newLogger(&buf, Options{Format: "text", MaxStringLength: 5}).Info("abcdefgh")
+
+	if got := buf.String(); !strings.Contains(got, "abcde…") || strings.Contains(got, "abcdef") {
+		t.Errorf("line = %q, want the message cut after 5 characters", got)
+	}
+	if !strings.Contains(buf.String(), `"_truncated":true`) {
+		t.Errorf("line = %q, want the truncation marker", buf.String())
+	}
+}
@@ -1,10 +1,12 @@
 package logger
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"io"
+	"log"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
@@ -15,71 +17,108 @@ import (
 type Options struct {
 	*slog.HandlerOptions
 
-	AddSource bool        // AddSource includes source file and line number in log output
-	Attr      []slog.Attr // Attr is a list of attributes to add to every log record
-	Format    string      // Format specifies output format: "json" or "text"
-	Level     string      // Level sets minimum log level: "debug", "info", "warn", or "error"
-	Pretty    bool        // Pretty enables JSON pretty-printing with indentation
-	Null      bool        // Null uses NullHandler to discard all logs (useful for testing)
+	AddSource   bool        // AddSource includes source file and line number in log output
+	Attr        []slog.Attr // Attr is a list of attributes to add to every log record
+	Format      string      // Format specifies output format: "json" or "text"
+	Output      io.Writer   // Output is the destination of records, e.g. a BufferedWriter (default os.Stdout)
+	Level       string      // Level sets minimum log level: "debug", "info", "warn", or "error"
+	Pretty      bool        // Pretty enables JSON pretty-printing with indentation
+	Null        bool        // Null uses NullHandler to discard all logs (useful for testing)
+	Stack       string      // Stack attaches a "stack" attribute to error records: "caller" (from the record's PC) or "goroutine"
+	StackDepth  int         // StackDepth limits the number of captured stack frames (default 32)
+	StackFilter bool        // StackFilter omits runtime and log/slog frames from the captured stack
+
+	SourceMode   string // SourceMode selects source rendering: "short" (default), "full", "relative", "base" or "func"
+	SourceStruct bool   // SourceStruct emits source as an object with file, line and function instead of a string
+	SourceLink   string // SourceLink renders text-mode source as an OSC 8 hyperlink, e.g. "vscode://file/{path}:{line}"
+
+	Replacers       []Replacer // Replacers are applied to every attribute after the built-in replacer, in order
+	ReplaceStandard bool       // ReplaceStandard passes the level, msg and time keys to Replacers in JSON format
+
+	Redact *Redaction // Redact removes sensitive keys and values from every record
+	Schema *Schema    // Schema drops or marks attributes that are not on the allow-list
+
+	TrustedText bool // TrustedText disables escaping of newlines and control characters in text output
+
+	MaxStringLength int // MaxStringLength truncates longer messages and string values, appending "…"
+	MaxAttrs        int // MaxAttrs limits the number of attributes per record
+	MaxDepth        int // MaxDepth limits the nesting depth of groups and maps
+	MaxElements     int // MaxElements limits the number of slice elements
+	MaxLineSize     int // MaxLineSize limits the size of an output line in bytes, dropping attributes and shortening the message if exceeded
+
+	Extractors []Extractor // Extractors derive attributes such as request or tenant IDs from the context of every record
+
+	TraceSource  TraceSource // TraceSource provides the active trace; traceparent values stored with WithTraceparent are used otherwise
+	TraceFormat  string      // TraceFormat selects trace keys: "otel" (trace_id, span_id, trace_flags, default), "datadog" or "gcp"
+	TraceProject string      // TraceProject is the Google Cloud project used to qualify trace names in "gcp" format
 }
 
 // NewLogger creates a new slog.Logger with the specified options.
 // If Null option is true, returns a logger with NullHandler that discards all output.
 // Otherwise, creates a custom handler with the configured format, level, and attributes.
 func NewLogger(opts Options) *slog.Logger {
-	// If Null option is set, return a logger with NullHandler
+	if opts.Output == nil {
+		return newLogger(os.Stdout, opts)
+	}
+
+	return newLogger(opts.Output, opts)
+}
+
+// newLogger creates a new slog.Logger writing to out, as described for NewLogger.
+func newLogger(out io.Writer, opts Options) *slog.Logger {
+	return slog.New(newHandler(out, opts))
+}
+
+// newHandler creates the handler of a logger writing to out, as described for NewLogger.
+func newHandler(out io.Writer, opts Options) slog.Handler {
+	// If Null option is set, return a NullHandler
 	if opts.Null {
-		return slog.New(NewNullHandler())
+		return NewNullHandler()
 	}
 
 	opts.HandlerOptions = &slog.HandlerOptions{
-		AddSource: opts.AddSource,
-		Level:     ParseLevel(opts.Level),
-		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-			// skip standart fields
-			if map[string]bool{
-				slog.LevelKey:   true,
-				slog.MessageKey: true,
-				slog.TimeKey:    true,
-			}[a.Key] {
-				return slog.Attr{}
-			}
-
-			key := strings.Split(a.Key, ";")
-
-			if a.Key == slog.SourceKey {
-				s := a.Value.Any().(*slog.Source)
-
-				dir, file := filepath.Split(s.File)
-
-				a.Value = slog.StringValue(fmt.Sprintf("%s:%d",
-					filepath.Join(filepath.Base(dir), file),
-					s.Line,
-				))
-			} else if key[0] == "raw" {
-				a.Key = strings.Join(key[1:], ";")
-				a.Value = slog.StringValue(fmt.Sprintf("%#v", a.Value.Any()))
-			}
-
-			return a
-		},
+		AddSource:   opts.AddSource,
+		Level:       ParseLevel(opts.Level),
+		ReplaceAttr: opts.replaceAttr(),
 	}
 
-	handler := NewHandler(os.Stdout, &opts)
+	handler := NewHandler(out, &opts)
 
-	return slog.New(handler.WithAttrs(opts.Attr))
+	return handler.WithAttrs(opts.Attr)
 }
 
 // SetGlobalLogger creates a new logger with the specified options and sets it as the default global logger.
 // This affects all subsequent calls to slog.Info(), slog.Debug(), etc. throughout the application.
-func SetGlobalLogger(opts Options) {
+// Output of the standard log package is routed through the logger as well, at info level
+// unless a line starts with a level prefix such as "[WARN]".
+//
+// The returned function flushes and closes the logger and is meant to be deferred in main:
+//
+//	shutdown := logger.SetGlobalLogger(opts)
+//	defer shutdown(context.Background())
+func SetGlobalLogger(opts Options) func(ctx context.Context) error {
 	logger := NewLogger(opts)
+	writer := NewWriter(logger, WriterOptions{ParseLevel: true})
 
 	slog.SetDefault(logger)
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(writer)
+
+	return func(ctx context.Context) error {
+		err := writer.(Flusher).Flush(ctx)
+
+		if c, ok := logger.Handler().(interface{ Close(context.Context) error }); ok {
+			err = errors.Join(err, c.Close(ctx))
+		}
+
+		return err
+	}
 }
 
 // ParseLevel converts a string representation of log level to slog.Level.
-// Valid inputs (case-insensitive): "debug", "info", "warn", "error".
+// Valid inputs (case-insensitive): "debug", "info", "warn", "error", "panic", "fatal".
 // Returns slog.LevelInfo for any unrecognized input as a safe default.
 func ParseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
@@ -89,6 +128,10 @@ func ParseLevel(level string) slog.Level {
 		return slog.LevelError
 	case "warn":
 		return slog.LevelWarn
+	case "panic":
+		return LevelPanic
+	case "fatal":
+		return LevelFatal
 	default:
 		return slog.LevelInfo
 	}
@@ -96,7 +139,7 @@ func ParseLevel(level string) slog.Level {
 
 // ParseColor returns a colorized string representation of the log level.
 // Colors are applied using fatih/color package: white (debug), green (info),
-// yellow (warn), red (error). Input is case-insensitive.
+// yellow (warn), red (error), magenta (panic and fatal). Input is case-insensitive.
 func ParseColor(level string) string {
 	switch strings.ToLower(level) {
 	case "debug":
@@ -105,7 +148,22 @@ func ParseColor(level string) string {
 		return color.RedString(level)
 	case "warn":
 		return color.YellowString(level)
+	case "panic", "fatal":
+		return color.MagentaString(level)
 	default:
 		return color.GreenString(level)
 	}
 }
+
+// levelName returns the name of a level, like slog.Level.String but with names for
+// LevelPanic and LevelFatal.
+func levelName(l slog.Level) string {
+	switch l {
+	case LevelPanic:
+		return "PANIC"
+	case LevelFatal:
+		return "FATAL"
+	default:
+		return l.String()
+	}
+}
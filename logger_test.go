@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"log"
 	"log/slog"
 	"os"
 	"strings"
@@ -148,6 +149,8 @@ func TestSetGlobalLogger(t *testing.T) {
 			// Save original default logger
 			originalLogger := slog.Default()
 			defer slog.SetDefault(originalLogger)
+			defer log.SetOutput(log.Writer())
+			defer log.SetFlags(log.Flags())
 
 			SetGlobalLogger(tt.opts)
 
@@ -212,6 +215,16 @@ func TestParseLevel(t *testing.T) {
 			level: "ERROR",
 			want:  slog.LevelError,
 		},
+		{
+			name:  "panic level",
+			level: "panic",
+			want:  LevelPanic,
+		},
+		{
+			name:  "fatal level uppercase",
+			level: "FATAL",
+			want:  LevelFatal,
+		},
 		{
 			name:  "invalid level defaults to info",
 			level: "invalid",
@@ -259,6 +272,14 @@ func TestParseColor(t *testing.T) {
 			name:  "uppercase debug",
 			level: "DEBUG",
 		},
+		{
+			name:  "panic level",
+			level: "panic",
+		},
+		{
+			name:  "fatal level",
+			level: "FATAL",
+		},
 		{
 			name:  "invalid level",
 			level: "invalid",
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestIDHeader is the header carrying the request ID if MiddlewareOptions.RequestIDHeader is empty.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength caps inbound request IDs; longer values are replaced by a generated ID.
+const maxRequestIDLength = 128
+
+// sampleFloat returns a pseudo-random number in [0.0,1.0) used for sampling; replaced in tests.
+var sampleFloat = mathrand.Float64
+
+// MiddlewareOptions configures the access-log middleware.
+type MiddlewareOptions struct {
+	Message         string                     // Message is the message of access-log records (default "http request")
+	RequestIDHeader string                     // RequestIDHeader is read for an inbound request ID and set on the response (default "X-Request-ID")
+	SkipPaths       []string                   // SkipPaths are request paths that are not logged, e.g. "/healthz"
+	Skip            func(r *http.Request) bool // Skip reports whether a request should not be logged
+	SampleRate      float64                    // SampleRate is the fraction of successful (below 400) requests logged; 0 logs all
+}
+
+// Middleware returns net/http middleware that logs one record per request to l.
+// The record holds the method, path, route pattern, status, bytes written, duration,
+// remote address, user agent and request ID, and is logged at error level for 5xx,
+// warn level for 4xx and info level otherwise.
+//
+// The request context carries a logger with the request ID, retrievable with FromContext,
+// and the trace of an inbound traceparent header.
+func Middleware(l *slog.Logger, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts.Message == "" {
+		opts.Message = "http request"
+	}
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = DefaultRequestIDHeader
+	}
+
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		skip[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] || opts.Skip != nil && opts.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			id := r.Header.Get(opts.RequestIDHeader)
+			if id == "" || len(id) > maxRequestIDLength {
+				id = newRequestID()
+			}
+			w.Header().Set(opts.RequestIDHeader, id)
+
+			ctx := WithTraceparent(r.Context(), r.Header.Get("traceparent"))
+			ctx = IntoContext(ctx, l.With(slog.String("request_id", id)))
+
+			rw := &responseWriter{ResponseWriter: w}
+			req := r.WithContext(ctx)
+
+			next.ServeHTTP(rw, req)
+
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+			}
+
+			if rw.status < 400 && opts.SampleRate > 0 && opts.SampleRate < 1 && sampleFloat() >= opts.SampleRate {
+				return
+			}
+
+			// ServeMux records the pattern on the request it was given
+			l.LogAttrs(ctx, statusLevel(rw.status), opts.Message,
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", routePattern(req)),
+				slog.Int("status", rw.status),
+				slog.Int64("bytes", rw.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("request_id", id),
+			)
+		})
+	}
+}
+
+// statusLevel returns the level for a response status: error for 5xx, warn for 4xx and info otherwise.
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// newRequestID returns a random 16-digit hex request ID.
+func newRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "0000000000000000"
+	}
+
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status and number of bytes written to an http.ResponseWriter.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status before passing it on.
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written; an implicit status is 200.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack implements http.Hijacker if the underlying writer supports it. A hijacked
+// connection is recorded with status 101, as it is usually upgraded to another protocol.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
@@ -0,0 +1,32 @@
+//go:build go1.23
+
+// Enhanced ServeMux patterns are disabled by default for the go version of go.mod.
+//go:debug httpmuxgo121=0
+
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware_Route(t *testing.T) {
+	var buf bytes.Buffer
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(http.ResponseWriter, *http.Request) {})
+
+	handler := Middleware(newLogger(&buf, Options{Format: "json"}), MiddlewareOptions{})(mux)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/42", nil))
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want 1", len(lines))
+	}
+
+	if lines[0]["route"] != "GET /items/{id}" {
+		t.Errorf("route = %v, want GET /items/{id}", lines[0]["route"])
+	}
+}
@@ -0,0 +1,295 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     MiddlewareOptions
+		request  func() *http.Request
+		handler  http.HandlerFunc
+		validate func(t *testing.T, rec *httptest.ResponseRecorder, lines []map[string]any)
+	}{
+		{
+			name: "successful request",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/items/42?q=1", nil)
+				r.Header.Set("User-Agent", "test-agent")
+				r.Header.Set("X-Request-ID", "req-1")
+				return r
+			},
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("hello"))
+			},
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 1 {
+					t.Fatalf("got %d records, want 1", len(lines))
+				}
+				out := lines[0]
+				want := map[string]any{
+					"level":       "info",
+					"msg":         "http request",
+					"method":      "GET",
+					"path":        "/items/42",
+					"status":      200.0,
+					"bytes":       5.0,
+					"remote_addr": "192.0.2.1:1234",
+					"user_agent":  "test-agent",
+					"request_id":  "req-1",
+				}
+				for k, v := range want {
+					if out[k] != v {
+						t.Errorf("%s = %v, want %v", k, out[k], v)
+					}
+				}
+				if _, ok := out["duration"].(float64); !ok {
+					t.Errorf("duration = %v, want a number", out["duration"])
+				}
+				if got := rec.Header().Get("X-Request-ID"); got != "req-1" {
+					t.Errorf("response X-Request-ID = %q, want req-1", got)
+				}
+			},
+		},
+		{
+			name: "client error logs at warn",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/missing", nil)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["level"] != "warn" || lines[0]["status"] != 404.0 {
+					t.Errorf("lines = %v, want one warn record with status 404", lines)
+				}
+			},
+		},
+		{
+			name: "server error logs at error",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/items", nil)
+			},
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["level"] != "error" || lines[0]["status"] != 500.0 {
+					t.Errorf("lines = %v, want one error record with status 500", lines)
+				}
+			},
+		},
+		{
+			name: "generated request id and scoped logger",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", nil)
+			},
+			handler: func(_ http.ResponseWriter, r *http.Request) {
+				FromContext(r.Context()).InfoContext(r.Context(), "inside")
+			},
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 2 {
+					t.Fatalf("got %d records, want 2", len(lines))
+				}
+				id := rec.Header().Get("X-Request-ID")
+				if len(id) != 16 {
+					t.Errorf("generated request id = %q, want 16 hex digits", id)
+				}
+				if lines[0]["msg"] != "inside" || lines[0]["request_id"] != id {
+					t.Errorf("scoped record = %v, want request_id %s", lines[0], id)
+				}
+				if lines[1]["request_id"] != id {
+					t.Errorf("access record request_id = %v, want %s", lines[1]["request_id"], id)
+				}
+			},
+		},
+		{
+			name: "skip paths",
+			opts: MiddlewareOptions{SkipPaths: []string{"/healthz"}},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			},
+			handler: func(http.ResponseWriter, *http.Request) {},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 0 {
+					t.Errorf("lines = %v, want none", lines)
+				}
+			},
+		},
+		{
+			name: "skip func",
+			opts: MiddlewareOptions{Skip: func(r *http.Request) bool { return r.Method == http.MethodOptions }},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodOptions, "/", nil)
+			},
+			handler: func(http.ResponseWriter, *http.Request) {},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 0 {
+					t.Errorf("lines = %v, want none", lines)
+				}
+			},
+		},
+		{
+			name: "sampled out success",
+			opts: MiddlewareOptions{SampleRate: 0.25},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", nil)
+			},
+			handler: func(http.ResponseWriter, *http.Request) {},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 0 {
+					t.Errorf("lines = %v, want none", lines)
+				}
+			},
+		},
+		{
+			name: "errors are never sampled out",
+			opts: MiddlewareOptions{SampleRate: 0.25},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", nil)
+			},
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 1 {
+					t.Errorf("lines = %v, want one record", lines)
+				}
+			},
+		},
+		{
+			name: "trace from traceparent",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("traceparent", testTraceparent)
+				return r
+			},
+			handler: func(http.ResponseWriter, *http.Request) {},
+			validate: func(t *testing.T, _ *httptest.ResponseRecorder, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+					t.Errorf("lines = %v, want trace_id", lines)
+				}
+			},
+		},
+	}
+
+	defer func(f func() float64) { sampleFloat = f }(sampleFloat)
+	sampleFloat = func() float64 { return 0.5 }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			handler := Middleware(newLogger(&buf, Options{Format: "json"}), tt.opts)(tt.handler)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.request())
+
+			tt.validate(t, rec, decodeLines(t, buf.String()))
+		})
+	}
+}
+
+func TestResponseWriter_ResponseController(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := Middleware(slog.New(NewNullHandler()), MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+		buf.WriteString("flushed")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(context.Background()))
+
+	if !rec.Flushed || buf.String() != "flushed" {
+		t.Errorf("Flushed = %v, want the recorder flushed", rec.Flushed)
+	}
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	var buf bytes.Buffer
+
+	done := make(chan struct{})
+	// both middlewares wrap the response writer
+	handler := Middleware(newLogger(&buf, Options{Format: "json"}), MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("response writer does not implement http.Hijacker")
+			return
+		}
+
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	}))
+	handler = RecoverMiddleware(slog.New(NewNullHandler()), RecoverOptions{})(handler)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("ReadResponse() error = %v", err)
+	}
+	resp.Body.Close()
+
+	<-done
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("StatusCode = %d, want 101", resp.StatusCode)
+	}
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 1 || lines[0]["status"] != 101.0 || lines[0]["path"] != "/ws" {
+		t.Errorf("lines = %v, want one record with status 101", lines)
+	}
+}
+
+// decodeLines decodes newline-delimited JSON records.
+func decodeLines(t *testing.T, s string) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line == "" {
+			continue
+		}
+
+		out := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &out); err != nil {
+			t.Fatalf("invalid output %q: %v", line, err)
+		}
+		lines = append(lines, out)
+	}
+
+	return lines
+}
@@ -38,3 +38,13 @@ func (h *NullHandler) WithAttrs(_ []slog.Attr) slog.Handler {
 func (h *NullHandler) WithGroup(_ string) slog.Handler {
 	return h
 }
+
+// Flush does nothing, as no records are written.
+func (h *NullHandler) Flush(_ context.Context) error {
+	return nil
+}
+
+// Close does nothing, as no records are written.
+func (h *NullHandler) Close(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !go1.23
+
+package logger
+
+import "net/http"
+
+// routePattern returns the ServeMux pattern that matched the request.
+// Patterns are only recorded by Go 1.23 and later.
+func routePattern(_ *http.Request) string {
+	return ""
+}
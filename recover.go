@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// panicStackDepth is the number of frames captured for recovered panics.
+const panicStackDepth = 128
+
+// RecoverOptions configures RecoverAndLog and RecoverMiddleware.
+type RecoverOptions struct {
+	Message string // Message is the message of panic records (default "panic recovered")
+	Level   string // Level is the level of panic records (default "error")
+	Repanic bool   // Repanic panics again with the recovered value after logging instead of swallowing it
+}
+
+// RecoverAndLog recovers a panic of the calling goroutine and logs it to l with the
+// panic value and the stack of the panicking goroutine. It must be deferred directly:
+//
+//	go func() {
+//		defer logger.RecoverAndLog(log)
+//		...
+//	}()
+//
+// The panic is swallowed unless opts has Repanic set.
+func RecoverAndLog(l *slog.Logger, opts ...RecoverOptions) {
+	if v := recover(); v != nil {
+		logPanic(context.Background(), l, v, recoverOptions(opts))
+	}
+}
+
+// RecoverAndLogContext is like RecoverAndLog, but logs with ctx so that the record carries
+// the attributes of the context, such as those stored with WithContextAttrs.
+func RecoverAndLogContext(ctx context.Context, l *slog.Logger, opts ...RecoverOptions) {
+	if v := recover(); v != nil {
+		logPanic(ctx, l, v, recoverOptions(opts))
+	}
+}
+
+// RecoverMiddleware returns net/http middleware that recovers panics of handlers and logs
+// them to l, with the method and path of the request and the attributes of its context.
+// Unless opts has Repanic set, the panic is swallowed and a 500 response is sent
+// if the handler has not written one yet.
+// http.ErrAbortHandler is passed on without logging, as net/http expects it.
+func RecoverMiddleware(l *slog.Logger, opts RecoverOptions) func(http.Handler) http.Handler {
+	opts = recoverOptions([]RecoverOptions{opts})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w}
+
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+
+				logPanic(r.Context(), l.With(slog.String("method", r.Method), slog.String("path", r.URL.Path)), v, opts)
+
+				if rw.status == 0 {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// recoverOptions returns the first of opts with defaults applied.
+func recoverOptions(opts []RecoverOptions) RecoverOptions {
+	var o RecoverOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Message == "" {
+		o.Message = "panic recovered"
+	}
+	if o.Level == "" {
+		o.Level = "error"
+	}
+
+	return o
+}
+
+// logPanic logs the recovered value v and re-panics if requested.
+// It must be called by the deferred function that recovered v.
+func logPanic(ctx context.Context, l *slog.Logger, v any, opts RecoverOptions) {
+	level := ParseLevel(opts.Level)
+
+	if l.Enabled(ctx, level) {
+		pc := panicPC()
+
+		r := slog.NewRecord(time.Now(), level, opts.Message, pc)
+		r.AddAttrs(
+			slog.Any("panic", panicValue(v)),
+			slog.Any("stack", callerFrames(2, pc, panicStackDepth, false)),
+		)
+
+		_ = l.Handler().Handle(ctx, r)
+	}
+
+	if opts.Repanic {
+		panic(v)
+	}
+}
+
+// panicValue returns the recovered value for logging; errors are kept so that
+// their message is logged, other values are formatted with %v.
+func panicValue(v any) any {
+	if err, ok := v.(error); ok {
+		return err
+	}
+
+	return fmt.Sprint(v)
+}
+
+// panicPC returns the program counter of the function that panicked, that is the first
+// frame below runtime.gopanic on the stack of the recovering goroutine, or zero.
+func panicPC() uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	panicking := false
+	for _, pc := range pcs {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+		switch {
+		case f.Function == "runtime.gopanic":
+			panicking = true
+		case panicking && !isRuntimeFrame(f.Function):
+			return pc
+		}
+	}
+
+	return 0
+}
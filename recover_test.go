@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicking() {
+	panic("boom")
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(l *slog.Logger)
+		wantPanic   bool
+		wantLevel   string
+		wantMessage string
+		wantPanicV  string
+		validate    func(t *testing.T, out map[string]any)
+	}{
+		{
+			name: "swallowed by default",
+			run: func(l *slog.Logger) {
+				defer RecoverAndLog(l)
+				panicking()
+			},
+			wantLevel:   "error",
+			wantMessage: "panic recovered",
+			wantPanicV:  "boom",
+		},
+		{
+			name: "error values",
+			run: func(l *slog.Logger) {
+				defer RecoverAndLog(l)
+				panic(errors.New("failed"))
+			},
+			wantLevel:   "error",
+			wantMessage: "panic recovered",
+			wantPanicV:  "failed",
+		},
+		{
+			name: "runtime errors",
+			run: func(l *slog.Logger) {
+				defer RecoverAndLog(l)
+				var m map[string]int
+				m["a"] = 1
+			},
+			wantLevel:   "error",
+			wantMessage: "panic recovered",
+			wantPanicV:  "assignment to entry in nil map",
+		},
+		{
+			name: "configured level and message",
+			run: func(l *slog.Logger) {
+				defer RecoverAndLog(l, RecoverOptions{Level: "warn", Message: "worker crashed"})
+				panicking()
+			},
+			wantLevel:   "warn",
+			wantMessage: "worker crashed",
+			wantPanicV:  "boom",
+		},
+		{
+			name: "repanic",
+			run: func(l *slog.Logger) {
+				defer RecoverAndLog(l, RecoverOptions{Repanic: true})
+				panicking()
+			},
+			wantPanic:   true,
+			wantLevel:   "error",
+			wantMessage: "panic recovered",
+			wantPanicV:  "boom",
+		},
+		{
+			name: "context attrs",
+			run: func(l *slog.Logger) {
+				ctx := WithContextAttrs(context.Background(), slog.String("job", "sync"))
+				defer RecoverAndLogContext(ctx, l)
+				panicking()
+			},
+			wantLevel:   "error",
+			wantMessage: "panic recovered",
+			wantPanicV:  "boom",
+			validate: func(t *testing.T, out map[string]any) {
+				if out["job"] != "sync" {
+					t.Errorf("job = %v, want sync", out["job"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			l := newLogger(&buf, Options{Format: "json", AddSource: true, Stack: "goroutine"})
+
+			func() {
+				defer func() {
+					if v := recover(); (v != nil) != tt.wantPanic {
+						t.Errorf("recovered %v, want panic %v", v, tt.wantPanic)
+					}
+				}()
+
+				tt.run(l)
+			}()
+
+			lines := decodeLines(t, buf.String())
+			if len(lines) != 1 {
+				t.Fatalf("got %d records, want 1", len(lines))
+			}
+
+			out := lines[0]
+			if out["level"] != tt.wantLevel || out["msg"] != tt.wantMessage {
+				t.Errorf("level, msg = %v, %v, want %v, %v", out["level"], out["msg"], tt.wantLevel, tt.wantMessage)
+			}
+			if p, _ := out["panic"].(string); !strings.Contains(p, tt.wantPanicV) {
+				t.Errorf("panic = %v, want %v", out["panic"], tt.wantPanicV)
+			}
+			if src, _ := out["source"].(string); !strings.Contains(src, "recover_test.go") {
+				t.Errorf("source = %v, want the panicking function", out["source"])
+			}
+
+			stack, _ := out["stack"].([]any)
+			if len(stack) == 0 {
+				t.Fatal("stack should not be empty")
+			}
+			if f, _ := stack[0].(map[string]any); strings.HasPrefix(f["func"].(string), "runtime.") {
+				t.Errorf("stack[0] = %v, want the panicking function", f)
+			}
+
+			if tt.validate != nil {
+				tt.validate(t, out)
+			}
+		})
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       RecoverOptions
+		handler    http.HandlerFunc
+		wantPanic  bool
+		wantStatus int
+		wantLines  int
+	}{
+		{
+			name: "panic before writing",
+			handler: func(http.ResponseWriter, *http.Request) {
+				panicking()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantLines:  1,
+		},
+		{
+			name: "panic after writing",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panicking()
+			},
+			wantStatus: http.StatusAccepted,
+			wantLines:  1,
+		},
+		{
+			name: "repanic",
+			opts: RecoverOptions{Repanic: true},
+			handler: func(http.ResponseWriter, *http.Request) {
+				panicking()
+			},
+			wantPanic:  true,
+			wantStatus: http.StatusOK,
+			wantLines:  1,
+		},
+		{
+			name: "abort handler",
+			handler: func(http.ResponseWriter, *http.Request) {
+				panic(http.ErrAbortHandler)
+			},
+			wantPanic:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "no panic",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			handler := RecoverMiddleware(newLogger(&buf, Options{Format: "json"}), tt.opts)(tt.handler)
+			rec := httptest.NewRecorder()
+
+			func() {
+				defer func() {
+					if v := recover(); (v != nil) != tt.wantPanic {
+						t.Errorf("recovered %v, want panic %v", v, tt.wantPanic)
+					}
+				}()
+
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+			}()
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			lines := decodeLines(t, buf.String())
+			if len(lines) != tt.wantLines {
+				t.Fatalf("got %d records %v, want %d", len(lines), lines, tt.wantLines)
+			}
+			if tt.wantLines > 0 && (lines[0]["method"] != "GET" || lines[0]["path"] != "/jobs") {
+				t.Errorf("out = %v, want method and path", lines[0])
+			}
+		})
+	}
+}
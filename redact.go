@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactPolicy selects how sensitive values are replaced.
+type RedactPolicy int
+
+const (
+	RedactMask    RedactPolicy = iota // RedactMask replaces the value with "[REDACTED]"
+	RedactPartial                     // RedactPartial keeps the first and last two characters of long values
+	RedactHash                        // RedactHash replaces the value with its HMAC-SHA256, keyed with Redaction.HashKey
+	RedactDrop                        // RedactDrop removes the attribute from the record
+)
+
+// ValueMatcher detects sensitive data inside string values.
+type ValueMatcher struct {
+	Name   string                  // Name identifies the matcher
+	Regexp *regexp.Regexp          // Regexp matches candidate substrings
+	Valid  func(match string) bool // Valid optionally confirms a candidate, e.g. with a checksum
+}
+
+// Built-in value matchers.
+var (
+	MatchJWT = ValueMatcher{
+		Name:   "jwt",
+		Regexp: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	}
+	MatchCreditCard = ValueMatcher{
+		Name:   "credit-card",
+		Regexp: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Valid:  luhn,
+	}
+	MatchAWSKey = ValueMatcher{
+		Name:   "aws-key",
+		Regexp: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	}
+	MatchEmail = ValueMatcher{
+		Name:   "email",
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+)
+
+// DefaultRedactKeys is a starting set of key patterns that usually hold secrets.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "*token*", "authorization", "cookie", "set-cookie", "*api_key*", "*apikey*",
+}
+
+// Redaction configures removal of sensitive data from log records.
+// It is applied to attributes at any group depth, including maps and structs
+// encoded as JSON objects and values rendered with the "raw;" directive.
+type Redaction struct {
+	Keys    []string       // Keys are case-insensitive key names or globs in path.Match syntax, e.g. "*token*"
+	Values  []ValueMatcher // Values match sensitive substrings of string values
+	Policy  RedactPolicy   // Policy selects how matches are replaced
+	HashKey []byte         // HashKey is the HMAC key used by RedactHash
+}
+
+// redactor applies a Redaction to decoded log records.
+type redactor struct {
+	keys    []string
+	values  []ValueMatcher
+	policy  RedactPolicy
+	hashKey []byte
+}
+
+// rawFieldRe matches `Field:"value"` and `"key":"value"` pairs produced by the %#v verb.
+var rawFieldRe = regexp.MustCompile(`(\b[A-Za-z_][A-Za-z0-9_]*|"(?:[^"\\]|\\.)*"):("(?:[^"\\]|\\.)*")`)
+
+// newRedactor prepares r for use by the handler. Returns nil if r is nil.
+func newRedactor(r *Redaction) *redactor {
+	if r == nil {
+		return nil
+	}
+
+	keys := make([]string, len(r.Keys))
+	for i, k := range r.Keys {
+		keys[i] = strings.ToLower(k)
+	}
+
+	return &redactor{
+		keys:    keys,
+		values:  r.Values,
+		policy:  r.Policy,
+		hashKey: r.HashKey,
+	}
+}
+
+// defaultRedactor is used by loggerRedactor for loggers without a configured Redaction.
+var defaultRedactor = newRedactor(&Redaction{Keys: DefaultRedactKeys})
+
+// loggerRedactor returns the redactor of l's Handler, or one for DefaultRedactKeys if it has none.
+// It is used to redact data, such as URLs and query arguments, before it is logged through l.
+func loggerRedactor(l *slog.Logger) *redactor {
+	if h, ok := l.Handler().(*Handler); ok && h.redact != nil {
+		return h.redact
+	}
+
+	return defaultRedactor
+}
+
+// matchKey reports whether key matches one of the sensitive key patterns.
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactMap redacts the decoded attributes in place.
+func (r *redactor) redactMap(m map[string]any) {
+	for k, v := range m {
+		if r.matchKey(k) {
+			if r.policy == RedactDrop {
+				delete(m, k)
+			} else {
+				m[k] = r.replace(plainString(v))
+			}
+
+			continue
+		}
+
+		if v, ok := r.redactValue(v); ok {
+			m[k] = v
+		} else {
+			delete(m, k)
+		}
+	}
+}
+
+// redactValue redacts a decoded JSON value. Returns false if the value must be dropped.
+func (r *redactor) redactValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		r.redactMap(v)
+	case []any:
+		kept := v[:0]
+		for _, e := range v {
+			if e, ok := r.redactValue(e); ok {
+				kept = append(kept, e)
+			}
+		}
+
+		return kept, true
+	case string:
+		s, matched := r.redactString(v)
+		if matched && r.policy == RedactDrop {
+			return nil, false
+		}
+
+		return s, true
+	}
+
+	return v, true
+}
+
+// redactString replaces sensitive substrings of s and reports whether anything matched.
+// Fields of values rendered with %#v are redacted by key as well.
+func (r *redactor) redactString(s string) (string, bool) {
+	matched := false
+
+	for _, m := range r.values {
+		s = m.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			if m.Valid != nil && !m.Valid(match) {
+				return match
+			}
+
+			matched = true
+
+			return r.replace(match)
+		})
+	}
+
+	if len(r.keys) > 0 && strings.Contains(s, `:"`) {
+		s = rawFieldRe.ReplaceAllStringFunc(s, func(field string) string {
+			sub := rawFieldRe.FindStringSubmatch(field)
+
+			key := sub[1]
+			if k, err := strconv.Unquote(key); err == nil {
+				key = k
+			}
+
+			if !r.matchKey(key) {
+				return field
+			}
+
+			matched = true
+
+			value := sub[2]
+			if v, err := strconv.Unquote(value); err == nil {
+				value = v
+			}
+
+			return sub[1] + ":" + strconv.Quote(r.replace(value))
+		})
+	}
+
+	return s, matched
+}
+
+// replace returns the replacement for a sensitive value according to the policy.
+func (r *redactor) replace(s string) string {
+	switch r.policy {
+	case RedactPartial:
+		n := utf8.RuneCountInString(s)
+		if n <= 8 {
+			return strings.Repeat("*", n)
+		}
+
+		runes := []rune(s)
+
+		return string(runes[:2]) + strings.Repeat("*", n-4) + string(runes[n-2:])
+	case RedactHash:
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(s))
+
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))
+	default:
+		return "[REDACTED]"
+	}
+}
+
+// plainString returns the string form of a decoded JSON value.
+func plainString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum.
+func luhn(s string) bool {
+	sum, n := 0, 0
+
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		n++
+	}
+
+	return n > 0 && sum%10 == 0
+}
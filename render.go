@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PanicKey holds the panic message when the handler itself fails while processing a record.
+const PanicKey = "!PANIC"
+
+// maxLogValuerDepth bounds the number of chained LogValue calls, as in log/slog.
+const maxLogValuerDepth = 100
+
+// cycleMarker replaces values that refer back to one of their parents.
+const cycleMarker = "<cycle>"
+
+// safeResolve resolves a LogValuer like slog.Value.Resolve, but reports panics in the
+// style of fmt, e.g. "!PANIC(LogValue): boom". Groups are resolved recursively.
+func safeResolve(v slog.Value) (resolved slog.Value) {
+	defer func() {
+		if p := recover(); p != nil {
+			resolved = slog.StringValue(fmt.Sprintf("!PANIC(LogValue): %v", p))
+		}
+	}()
+
+	for i := 0; v.Kind() == slog.KindLogValuer; i++ {
+		if i == maxLogValuerDepth {
+			return slog.StringValue("!ERROR(LogValue): too many nested LogValue calls")
+		}
+
+		v = v.LogValuer().LogValue()
+	}
+
+	if v.Kind() == slog.KindGroup && needsResolve(v.Group()) {
+		return slog.GroupValue(resolveAttrs(v.Group())...)
+	}
+
+	return v
+}
+
+// resolveAttrs returns attrs with all LogValuers safely resolved. Key directives of groups
+// are applied here, as slog does not pass groups to ReplaceAttr.
+func resolveAttrs(attrs []slog.Attr) []slog.Attr {
+	if !needsResolve(attrs) {
+		return attrs
+	}
+
+	resolved := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a = slog.Attr{Key: a.Key, Value: safeResolve(a.Value)}
+
+		if a.Value.Kind() == slog.KindGroup {
+			var ok bool
+			if a, ok = applyDirectives(a); !ok {
+				continue
+			}
+		}
+
+		resolved = append(resolved, a)
+	}
+
+	return resolved
+}
+
+// resolveRecord returns r with all LogValuers safely resolved.
+func resolveRecord(r slog.Record) slog.Record {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	if !needsResolve(attrs) {
+		return r
+	}
+
+	resolved := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	resolved.AddAttrs(resolveAttrs(attrs)...)
+
+	return resolved
+}
+
+// needsResolve reports whether attrs contain a LogValuer or a group with key directives at any depth.
+func needsResolve(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindLogValuer:
+			return true
+		case slog.KindGroup:
+			if strings.Contains(a.Key, ";") || needsResolve(a.Value.Group()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// safeValue protects the JSON encoding of arbitrary values: errors are rendered
+// with a recovered Error call, other values are encoded by safeJSON.
+func safeValue(v slog.Value) slog.Value {
+	if v.Kind() != slog.KindAny || v.Any() == nil {
+		return v
+	}
+
+	switch x := v.Any().(type) {
+	case error:
+		return slog.StringValue(safeCall("Error", x.Error))
+	case json.Marshaler:
+		return slog.AnyValue(safeJSON{x})
+	}
+
+	return slog.AnyValue(safeJSON{v.Any()})
+}
+
+// safeJSON encodes a value with encoding/json, recovering from panics in custom
+// marshalers and replacing cyclic values with their Go syntax representation.
+type safeJSON struct {
+	v any
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s safeJSON) MarshalJSON() (b []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			b, err = json.Marshal(fmt.Sprintf("!PANIC(MarshalJSON): %v", p))
+		}
+	}()
+
+	if b, err = json.Marshal(s.v); err == nil {
+		return b, nil
+	}
+
+	if hasCycle(reflect.ValueOf(s.v), map[visit]bool{}) {
+		return json.Marshal(sprintRaw(s.v))
+	}
+
+	return json.Marshal("!ERROR:" + err.Error())
+}
+
+// safeCall calls fn, reporting a panic in the style of fmt, e.g. "!PANIC(Error): boom".
+func safeCall(method string, fn func() string) (s string) {
+	defer func() {
+		if p := recover(); p != nil {
+			s = fmt.Sprintf("!PANIC(%s): %v", method, p)
+		}
+	}()
+
+	return fn()
+}
+
+// sprintRaw renders v in Go syntax like the %#v verb. Panics in GoString, String
+// and Error methods are reported inline by fmt; values referring back to one of
+// their parents are replaced with "<cycle>".
+func sprintRaw(v any) (s string) {
+	defer func() {
+		if p := recover(); p != nil {
+			s = fmt.Sprintf("!PANIC(GoString): %v", p)
+		}
+	}()
+
+	rv := reflect.ValueOf(v)
+	if !hasCycle(rv, map[visit]bool{}) {
+		return fmt.Sprintf("%#v", v)
+	}
+
+	var b strings.Builder
+	writeRaw(&b, rv, map[visit]bool{}, true)
+
+	return b.String()
+}
+
+// visit identifies a pointer, map or slice on the current path of a value walk.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enter records v on the walk path. It returns false if v is already on the path,
+// and a function removing v from the path otherwise.
+func enter(v reflect.Value, path map[visit]bool) (func(), bool) {
+	if v.IsNil() {
+		return func() {}, true
+	}
+
+	key := visit{v.Pointer(), v.Type()}
+	if path[key] {
+		return nil, false
+	}
+
+	path[key] = true
+
+	return func() { delete(path, key) }, true
+}
+
+// hasCycle reports whether v refers back to one of its parents.
+func hasCycle(v reflect.Value, path map[visit]bool) bool {
+	if !v.IsValid() || !hasRefs(v.Type()) {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		leave, ok := enter(v, path)
+		if !ok {
+			return true
+		}
+		defer leave()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil() && hasCycle(v.Elem(), path)
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			if hasCycle(it.Value(), path) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if hasCycle(v.Index(i), path) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if hasCycle(v.Field(i), path) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasRefs reports whether values of type t may contain pointers, maps, slices or interfaces.
+func hasRefs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	case reflect.Array:
+		return hasRefs(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasRefs(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// writeRaw writes v in Go syntax, replacing cyclic references with "<cycle>".
+// Method calls are skipped for composite values, so custom GoString methods only
+// apply to leaf values.
+func writeRaw(b *strings.Builder, v reflect.Value, path map[visit]bool, top bool) {
+	if !v.IsValid() {
+		b.WriteString("<nil>")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			fmt.Fprintf(b, "%#v", v)
+			return
+		}
+
+		leave, ok := enter(v, path)
+		if !ok {
+			b.WriteString(cycleMarker)
+			return
+		}
+		defer leave()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if e := v.Elem(); top || e.Kind() == reflect.Struct || e.Kind() == reflect.Map || e.Kind() == reflect.Slice {
+			b.WriteString("&")
+			writeRaw(b, e, path, false)
+		} else {
+			fmt.Fprintf(b, "(%s)(%#x)", v.Type(), v.Pointer())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString(v.Type().String() + "(nil)")
+			return
+		}
+
+		writeRaw(b, v.Elem(), path, false)
+	case reflect.Map:
+		b.WriteString(v.Type().String() + "{")
+
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%#v", keys[i]) < fmt.Sprintf("%#v", keys[j])
+		})
+
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			writeRaw(b, k, path, false)
+			b.WriteString(":")
+			writeRaw(b, v.MapIndex(k), path, false)
+		}
+
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString(v.Type().String() + "{")
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			writeRaw(b, v.Index(i), path, false)
+		}
+
+		b.WriteString("}")
+	case reflect.Struct:
+		b.WriteString(v.Type().String() + "{")
+
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			b.WriteString(v.Type().Field(i).Name + ":")
+			writeRaw(b, v.Field(i), path, false)
+		}
+
+		b.WriteString("}")
+	default:
+		fmt.Fprintf(b, "%#v", v)
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type panicValuer struct{}
+
+func (panicValuer) LogValue() slog.Value { panic("boom") }
+
+type panicError struct{}
+
+func (panicError) Error() string { panic("boom") }
+
+type panicGoStringer struct{}
+
+func (panicGoStringer) GoString() string { panic("boom") }
+
+type panicMarshaler struct{}
+
+func (panicMarshaler) MarshalJSON() ([]byte, error) { panic("boom") }
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestSafeRendering(t *testing.T) {
+	cyclicMap := map[string]any{"a": 1}
+	cyclicMap["self"] = cyclicMap
+
+	cyclicNode := &node{Name: "a"}
+	cyclicNode.Next = cyclicNode
+
+	tests := []struct {
+		name    string
+		logFunc func(logger *slog.Logger)
+		key     string
+		want    string
+	}{
+		{
+			name: "raw cyclic map",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "raw;value", cyclicMap)
+			},
+			key:  "value",
+			want: `map[string]interface {}{"a":1, "self":<cycle>}`,
+		},
+		{
+			name: "raw cyclic pointer",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "raw;value", cyclicNode)
+			},
+			key:  "value",
+			want: `&logger.node{Name:"a", Next:<cycle>}`,
+		},
+		{
+			name: "json cyclic map",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "value", cyclicMap)
+			},
+			key:  "value",
+			want: `map[string]interface {}{"a":1, "self":<cycle>}`,
+		},
+		{
+			name: "panicking LogValue",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "value", panicValuer{})
+			},
+			key:  "value",
+			want: "!PANIC(LogValue): boom",
+		},
+		{
+			name: "panicking LogValue in WithAttrs",
+			logFunc: func(logger *slog.Logger) {
+				logger.With("value", panicValuer{}).Info("test")
+			},
+			key:  "value",
+			want: "!PANIC(LogValue): boom",
+		},
+		{
+			name: "panicking LogValue in group",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", slog.Group("g", "value", panicValuer{}))
+			},
+			key:  "g",
+			want: `map[value:!PANIC(LogValue): boom]`,
+		},
+		{
+			name: "panicking Error",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "value", panicError{})
+			},
+			key:  "value",
+			want: "!PANIC(Error): boom",
+		},
+		{
+			name: "panicking GoString",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "raw;value", panicGoStringer{})
+			},
+			key:  "value",
+			want: "%!v(PANIC=GoString method: boom)",
+		},
+		{
+			name: "panicking MarshalJSON",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "value", panicMarshaler{})
+			},
+			key:  "value",
+			want: "!PANIC(MarshalJSON): boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.logFunc(newLogger(&buf, Options{Format: "json"}))
+
+			out := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			got, ok := out[tt.key].(string)
+			if !ok {
+				b, _ := json.Marshal(out[tt.key])
+				got = strings.NewReplacer(`{"`, "map[", `":"`, ":", `"}`, "]").Replace(string(b))
+			}
+
+			if got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+			}
+			if out["msg"] != "test" {
+				t.Errorf("msg = %v, want test", out["msg"])
+			}
+		})
+	}
+}
+
+func TestHandler_HandlePanic(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{
+		HandlerOptions: &slog.HandlerOptions{
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == "value" {
+					panic("boom")
+				}
+				return a
+			},
+		},
+		Format: "json",
+	}
+
+	handler := NewHandler(&buf, &opts)
+	slog.New(&handler).Info("still written", "value", 1)
+
+	out := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+
+	if out["msg"] != "still written" {
+		t.Errorf("msg = %v, want still written", out["msg"])
+	}
+	if p, _ := out[PanicKey].(string); !strings.HasPrefix(p, "!PANIC(Handle): ") {
+		t.Errorf("%s = %v, want panic message", PanicKey, out[PanicKey])
+	}
+}
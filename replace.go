@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Replacer rewrites an attribute before it is logged, with the same semantics as
+// slog.HandlerOptions.ReplaceAttr. Returning an attribute with an empty key drops it.
+type Replacer func(groups []string, a slog.Attr) slog.Attr
+
+// replaceAttr builds the ReplaceAttr chain used by NewLogger. Replacers run in this order:
+//
+//  1. the built-in replacer, which strips the level, msg and time keys (or normalises
+//     them if ReplaceStandard is set in JSON format), shortens the source and applies
+//     key directives such as "raw;";
+//  2. the ReplaceAttr function of opts.HandlerOptions, if any;
+//  3. opts.Replacers, in order.
+//
+// The chain stops as soon as an attribute is dropped. Values of the resulting attribute
+// are finally wrapped so that panics and cycles in their JSON encoding are reported inline.
+func (opts *Options) replaceAttr() func(groups []string, a slog.Attr) slog.Attr {
+	chain := []Replacer{opts.builtinReplacer()}
+
+	if opts.HandlerOptions != nil && opts.HandlerOptions.ReplaceAttr != nil {
+		chain = append(chain, opts.HandlerOptions.ReplaceAttr)
+	}
+
+	chain = append(chain, opts.Replacers...)
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		for _, replace := range chain {
+			if a = replace(groups, a); a.Key == "" {
+				return slog.Attr{}
+			}
+		}
+
+		a.Value = safeValue(a.Value)
+
+		return a
+	}
+}
+
+// builtinReplacer returns the replacer handling standard keys, source and key directives.
+func (opts *Options) builtinReplacer() Replacer {
+	standard := opts.ReplaceStandard && opts.Format != "text"
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.LevelKey, slog.MessageKey, slog.TimeKey:
+				// the handler writes standard fields itself unless they are passed on
+				if !standard {
+					return slog.Attr{}
+				}
+
+				return standardAttr(a)
+			}
+		}
+
+		if a.Key == slog.SourceKey {
+			if s, ok := a.Value.Any().(*slog.Source); ok {
+				a.Value = sourceValue(opts.SourceMode, opts.SourceStruct, s)
+			}
+
+			return a
+		}
+
+		// apply key directives such as "raw;"
+		if a, ok := applyDirectives(a); ok {
+			return a
+		}
+
+		return slog.Attr{}
+	}
+}
+
+// standardAttr formats the level and time attributes the way Handler.Handle does.
+func standardAttr(a slog.Attr) slog.Attr {
+	switch a.Key {
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(strings.ToLower(levelName(l)))
+		}
+	case slog.TimeKey:
+		if a.Value.Kind() == slog.KindTime {
+			a.Value = slog.StringValue(a.Value.Time().Format(time.DateTime))
+		}
+	}
+
+	return a
+}
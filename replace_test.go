@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestOptions_Replacers(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		logFunc  func(logger *slog.Logger)
+		validate func(t *testing.T, out map[string]any)
+	}{
+		{
+			name: "user replacer runs after built-in",
+			opts: Options{
+				Format: "json",
+				Replacers: []Replacer{
+					func(_ []string, a slog.Attr) slog.Attr {
+						if a.Key == "key" {
+							a.Value = slog.StringValue(strings.ToUpper(a.Value.String()))
+						}
+						return a
+					},
+				},
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "raw;key", "value")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["key"] != `"VALUE"` {
+					t.Errorf("key = %v, want %q", out["key"], `"VALUE"`)
+				}
+			},
+		},
+		{
+			name: "handler options replacer is kept",
+			opts: Options{
+				HandlerOptions: &slog.HandlerOptions{
+					ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+						if a.Key == "drop" {
+							return slog.Attr{}
+						}
+						return a
+					},
+				},
+				Format: "json",
+				Replacers: []Replacer{
+					func(_ []string, a slog.Attr) slog.Attr {
+						if a.Key == "drop" {
+							t.Error("chain should stop after an attribute is dropped")
+						}
+						return a
+					},
+				},
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", "drop", 1, "keep", 2)
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if _, ok := out["drop"]; ok {
+					t.Error("drop should be removed")
+				}
+				if _, ok := out["keep"]; !ok {
+					t.Error("keep should be logged")
+				}
+			},
+		},
+		{
+			name: "standard keys are hidden from replacers by default",
+			opts: Options{
+				Format: "json",
+				Replacers: []Replacer{
+					func(groups []string, a slog.Attr) slog.Attr {
+						if len(groups) == 0 && a.Key == slog.LevelKey {
+							t.Error("level should not reach user replacers")
+						}
+						return a
+					},
+				},
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Warn("test")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["level"] != "warn" {
+					t.Errorf("level = %v, want warn", out["level"])
+				}
+			},
+		},
+		{
+			name: "standard keys can be rewritten",
+			opts: Options{
+				Format:          "json",
+				ReplaceStandard: true,
+				Replacers: []Replacer{
+					func(groups []string, a slog.Attr) slog.Attr {
+						if len(groups) == 0 && a.Key == slog.LevelKey {
+							a.Key = "severity"
+						}
+						if len(groups) == 0 && a.Key == slog.TimeKey {
+							return slog.Attr{}
+						}
+						return a
+					},
+				},
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Warn("test")
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["severity"] != "warn" {
+					t.Errorf("severity = %v, want warn", out["severity"])
+				}
+				if _, ok := out["level"]; ok {
+					t.Error("level should be renamed")
+				}
+				if _, ok := out["time"]; ok {
+					t.Error("time should be dropped")
+				}
+				if out["msg"] != "test" {
+					t.Errorf("msg = %v, want test", out["msg"])
+				}
+			},
+		},
+		{
+			name: "standard keys in groups are not stripped",
+			opts: Options{
+				Format: "json",
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("test", slog.Group("req", "time", "1s"))
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				req, _ := out["req"].(map[string]any)
+				if req["time"] != "1s" {
+					t.Errorf("req = %v, want time=1s", out["req"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			tt.logFunc(newLogger(&buf, tt.opts))
+
+			out := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			tt.validate(t, out)
+		})
+	}
+}
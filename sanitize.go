@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// escapeControl escapes characters that could forge log lines or drive the terminal:
+// C0 controls including newlines and ESC, DEL, C1 controls, the Unicode line and
+// paragraph separators and invalid UTF-8 bytes.
+func escapeControl(s string) string {
+	if !hasControl(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case isUnsafeRune(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+
+		i += size
+	}
+
+	return b.String()
+}
+
+// hasControl reports whether s contains anything escapeControl would escape.
+func hasControl(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || r < 0x20 || r == 0x7f || isUnsafeRune(r) {
+			return true
+		}
+
+		i += size
+	}
+
+	return false
+}
+
+// isUnsafeRune reports whether r is a C1 control or a Unicode line or paragraph separator.
+func isUnsafeRune(r rune) bool {
+	return r >= 0x80 && r <= 0x9f || r == '\u2028' || r == '\u2029'
+}
+
+// sanitizeMap escapes control characters in the keys and string values of decoded
+// attributes, at any depth. The JSON encoder already escapes C0 controls, so only the
+// characters it passes through unchanged are escaped here.
+func sanitizeMap(m map[string]any) {
+	for k, v := range m {
+		v = sanitizeValue(v)
+
+		if key := escapeUnsafe(k); key != k {
+			delete(m, k)
+			k = key
+		}
+
+		m[k] = v
+	}
+}
+
+// sanitizeValue escapes control characters in a decoded JSON value.
+func sanitizeValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		sanitizeMap(v)
+	case []any:
+		for i := range v {
+			v[i] = sanitizeValue(v[i])
+		}
+	case string:
+		return escapeUnsafe(v)
+	}
+
+	return v
+}
+
+// escapeUnsafe escapes DEL, C1 controls and the Unicode separators, which the JSON
+// encoder does not escape.
+func escapeUnsafe(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { return r == 0x7f || isUnsafeRune(r) }) {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if r == 0x7f || isUnsafeRune(r) {
+			fmt.Fprintf(&b, `\u%04x`, r)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
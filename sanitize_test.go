@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+func TestEscapeControl(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain text is unchanged",
+			in:   "hello, wörld",
+			want: "hello, wörld",
+		},
+		{
+			name: "newlines",
+			in:   "a\nb\r\nc",
+			want: `a\nb\r\nc`,
+		},
+		{
+			name: "escape sequences",
+			in:   "\x1b[2Jcleared",
+			want: `\x1b[2Jcleared`,
+		},
+		{
+			name: "del and c1 controls",
+			in:   "a\x7fb\u009bc",
+			want: `a\x7fb\u009bc`,
+		},
+		{
+			name: "line separator",
+			in:   "a\u2028b",
+			want: `a\u2028b`,
+		},
+		{
+			name: "invalid utf-8",
+			in:   "a\x9bb",
+			want: `a\x9bb`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeControl(tt.in); got != tt.want {
+				t.Errorf("escapeControl(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_TextSanitize(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	tests := []struct {
+		name      string
+		trusted   bool
+		wantLines int
+	}{
+		{
+			name:      "messages are escaped",
+			wantLines: 1,
+		},
+		{
+			name:      "trusted text is kept",
+			trusted:   true,
+			wantLines: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := newLogger(&buf, Options{
+				Format:      "text",
+				TrustedText: tt.trusted,
+			})
+			logger.Info("login ok\n2024-01-01 00:00:00 INFO forged", "user\x1b[31m", "bob\u009b")
+
+			if got := strings.Count(buf.String(), "\n"); got != tt.wantLines {
+				t.Errorf("lines = %d, want %d: %q", got, tt.wantLines, buf.String())
+			}
+			if !tt.trusted && strings.ContainsAny(buf.String(), "\x1b\u009b") {
+				t.Errorf("output contains control characters: %q", buf.String())
+			}
+		})
+	}
+}
+
+func FuzzHandler_TextOneLine(f *testing.F) {
+	f.Add("message", "key", "value")
+	f.Add("a\nb", "k\r\n", "\x1b[2J")
+	f.Add("\u2028", "\u009b", "\x7f")
+	f.Add("\xff\xfe", "raw;key", "100%")
+
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = true
+
+	f.Fuzz(func(t *testing.T, msg, key, value string) {
+		var buf bytes.Buffer
+
+		logger := newLogger(&buf, Options{Format: "text"})
+		logger.Info(msg, key, value, slog.Group("group", key, value))
+
+		out := buf.String()
+		if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, "\n") {
+			t.Fatalf("record is not exactly one line: %q", out)
+		}
+
+		for i, r := range strings.TrimSuffix(out, "\n") {
+			if r < 0x20 || r == 0x7f || r >= 0x80 && r <= 0x9f || r == '\u2028' || r == '\u2029' {
+				t.Fatalf("control character %U at %d: %q", r, i, out)
+			}
+			if r == utf8.RuneError && !strings.ContainsRune(msg+key+value, utf8.RuneError) {
+				if _, size := utf8.DecodeRuneInString(out[i:]); size == 1 {
+					t.Fatalf("invalid utf-8 at %d: %q", i, out)
+				}
+			}
+		}
+	})
+}
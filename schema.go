@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SchemaMarker replaces the value of attributes that violate a Schema unless Schema.Drop is set.
+const SchemaMarker = "!SCHEMA"
+
+// Schema is an allow-list of the attributes records may carry, for services where
+// only reviewed fields may reach the logs. Attributes are addressed by their dotted
+// group path, e.g. "http.method" for the key "method" inside the group "http".
+// The standard level, msg and time keys, source and stack are always permitted.
+// A Schema may be shared by several handlers; its violation counter is safe for concurrent use.
+type Schema struct {
+	Fields      map[string]slog.Kind      // Fields maps permitted paths to their kind; slog.KindAny accepts any value
+	Drop        bool                      // Drop removes violating attributes instead of replacing them with SchemaMarker
+	OnViolation func(path, reason string) // OnViolation is called for every violation, e.g. to fail a test
+
+	once       sync.Once
+	groups     map[string]bool // groups holds the prefixes of declared paths
+	violations atomic.Int64
+}
+
+// builtinKeys are the top-level keys produced by the handler itself.
+var builtinKeys = map[string]bool{
+	slog.LevelKey:   true,
+	slog.MessageKey: true,
+	slog.TimeKey:    true,
+	slog.SourceKey:  true,
+	"stack":         true,
+}
+
+// Violations returns the number of violations recorded since the schema was created.
+func (s *Schema) Violations() int64 {
+	return s.violations.Load()
+}
+
+// enforce removes or marks attributes of the decoded record that are not permitted.
+func (s *Schema) enforce(attrs map[string]any) {
+	s.once.Do(func() {
+		s.groups = map[string]bool{}
+		for path := range s.Fields {
+			for i, c := range path {
+				if c == '.' {
+					s.groups[path[:i]] = true
+				}
+			}
+		}
+	})
+
+	s.enforceGroup("", attrs)
+}
+
+// enforceGroup checks the attributes of the group at prefix.
+func (s *Schema) enforceGroup(prefix string, m map[string]any) {
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		if prefix == "" && builtinKeys[k] {
+			continue
+		}
+
+		kind, declared := s.Fields[path]
+
+		switch {
+		case !declared && s.groups[path]:
+			if group, ok := v.(map[string]any); ok {
+				s.enforceGroup(path, group)
+				continue
+			}
+
+			s.violate(m, k, path, "expected group, got "+jsonKind(v))
+		case !declared:
+			s.violate(m, k, path, "unexpected key")
+		case kind == slog.KindGroup:
+			if group, ok := v.(map[string]any); ok {
+				s.enforceGroup(path, group)
+				continue
+			}
+
+			s.violate(m, k, path, "expected group, got "+jsonKind(v))
+		case !kindMatches(kind, v):
+			s.violate(m, k, path, fmt.Sprintf("expected %s, got %s", strings.ToLower(kind.String()), jsonKind(v)))
+		}
+	}
+}
+
+// violate records a violation and drops or marks the attribute.
+func (s *Schema) violate(m map[string]any, key, path, reason string) {
+	s.violations.Add(1)
+
+	if s.OnViolation != nil {
+		s.OnViolation(path, reason)
+	}
+
+	if s.Drop {
+		delete(m, key)
+	} else {
+		m[key] = SchemaMarker
+	}
+}
+
+// kindMatches reports whether a decoded JSON value is compatible with the slog kind.
+func kindMatches(kind slog.Kind, v any) bool {
+	switch kind {
+	case slog.KindString, slog.KindTime:
+		_, ok := v.(string)
+		return ok
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindDuration:
+		_, ok := v.(float64)
+		return ok
+	case slog.KindBool:
+		_, ok := v.(bool)
+		return ok
+	default:
+		return true
+	}
+}
+
+// jsonKind names the type of a decoded JSON value.
+func jsonKind(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case map[string]any:
+		return "group"
+	case []any:
+		return "array"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	fields := map[string]slog.Kind{
+		"user":        slog.KindString,
+		"count":       slog.KindInt64,
+		"http.method": slog.KindString,
+		"http.status": slog.KindInt64,
+		"payload":     slog.KindAny,
+		"meta":        slog.KindGroup,
+		"meta.id":     slog.KindString,
+	}
+
+	tests := []struct {
+		name           string
+		drop           bool
+		logFunc        func(logger *slog.Logger)
+		wantViolations []string
+		validate       func(t *testing.T, out map[string]any)
+	}{
+		{
+			name: "permitted attributes pass",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("ok",
+					"user", "bob",
+					"count", 3,
+					slog.Group("http", "method", "GET", "status", 200),
+					"payload", []int{1, 2},
+					slog.Group("meta", "id", "x"),
+				)
+			},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["user"] != "bob" {
+					t.Errorf("user = %v, want bob", out["user"])
+				}
+				if out["msg"] != "ok" {
+					t.Errorf("msg = %v, want ok", out["msg"])
+				}
+			},
+		},
+		{
+			name: "unexpected keys are marked",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("pan", "pan", "4111111111111111", slog.Group("http", "cookie", "c"))
+			},
+			wantViolations: []string{"http.cookie", "pan"},
+			validate: func(t *testing.T, out map[string]any) {
+				if out["pan"] != SchemaMarker {
+					t.Errorf("pan = %v, want %s", out["pan"], SchemaMarker)
+				}
+				if http, _ := out["http"].(map[string]any); http["cookie"] != SchemaMarker {
+					t.Errorf("http = %v, want cookie marked", out["http"])
+				}
+			},
+		},
+		{
+			name: "unexpected keys are dropped",
+			drop: true,
+			logFunc: func(logger *slog.Logger) {
+				logger.With("pan", "4111111111111111").Info("pan", "user", "bob")
+			},
+			wantViolations: []string{"pan"},
+			validate: func(t *testing.T, out map[string]any) {
+				if _, ok := out["pan"]; ok {
+					t.Error("pan should be dropped")
+				}
+				if out["user"] != "bob" {
+					t.Errorf("user = %v, want bob", out["user"])
+				}
+			},
+		},
+		{
+			name: "kinds are checked",
+			logFunc: func(logger *slog.Logger) {
+				logger.Info("kinds", "user", 42, "count", "three", "meta", "flat")
+			},
+			wantViolations: []string{"count", "meta", "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				buf        bytes.Buffer
+				violations []string
+			)
+
+			schema := &Schema{
+				Fields: fields,
+				Drop:   tt.drop,
+				OnViolation: func(path, _ string) {
+					violations = append(violations, path)
+				},
+			}
+
+			tt.logFunc(newLogger(&buf, Options{
+				Format:    "json",
+				AddSource: true,
+				Schema:    schema,
+			}))
+
+			sort.Strings(violations)
+			if len(violations) != len(tt.wantViolations) {
+				t.Fatalf("violations = %v, want %v", violations, tt.wantViolations)
+			}
+			for i := range violations {
+				if violations[i] != tt.wantViolations[i] {
+					t.Errorf("violations = %v, want %v", violations, tt.wantViolations)
+				}
+			}
+			if schema.Violations() != int64(len(tt.wantViolations)) {
+				t.Errorf("Violations() = %d, want %d", schema.Violations(), len(tt.wantViolations))
+			}
+
+			if tt.validate != nil {
+				out := map[string]any{}
+				if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+					t.Fatalf("invalid output %q: %v", buf.String(), err)
+				}
+
+				tt.validate(t, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"fmt"
+	"go/build"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// moduleRoots caches the module root found for each source directory.
+var moduleRoots sync.Map
+
+// sourceValue renders a slog.Source according to the source mode.
+// Supported modes are "full" (absolute path), "relative" (path relative to the module
+// root or GOPATH), "base" (file name only), "func" (function name followed by file:line)
+// and "short" (parent directory and file name, the default).
+// If structured is true, the result is a group with file, line and function attributes.
+func sourceValue(mode string, structured bool, s *slog.Source) slog.Value {
+	file := sourceFile(mode, s.File)
+
+	if structured {
+		return slog.GroupValue(
+			slog.String("file", file),
+			slog.Int("line", s.Line),
+			slog.String("function", s.Function),
+		)
+	}
+
+	if mode == "func" {
+		return slog.StringValue(fmt.Sprintf("%s %s:%d", shortFunction(s.Function), file, s.Line))
+	}
+
+	return slog.StringValue(fmt.Sprintf("%s:%d", file, s.Line))
+}
+
+// sourceFile shortens the file path according to the source mode.
+func sourceFile(mode, path string) string {
+	switch mode {
+	case "full":
+		return path
+	case "relative":
+		return relativePath(path)
+	case "base", "func":
+		return filepath.Base(path)
+	default:
+		dir, file := filepath.Split(path)
+
+		return filepath.Join(filepath.Base(dir), file)
+	}
+}
+
+// relativePath returns path relative to the module cache, GOPATH/src or the
+// nearest directory containing go.mod. The full path is returned if none match.
+func relativePath(path string) string {
+	slashed := filepath.ToSlash(path)
+
+	if i := strings.Index(slashed, "/pkg/mod/"); i >= 0 {
+		return slashed[i+len("/pkg/mod/"):]
+	}
+
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		src := filepath.Join(gopath, "src") + string(filepath.Separator)
+		if strings.HasPrefix(path, src) {
+			return filepath.ToSlash(path[len(src):])
+		}
+	}
+
+	if root := moduleRoot(filepath.Dir(path)); root != "" {
+		if rel, err := filepath.Rel(root, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	return path
+}
+
+// moduleRoot walks up from dir looking for go.mod and returns the containing directory.
+// Returns an empty string if no module root is found.
+func moduleRoot(dir string) string {
+	if root, ok := moduleRoots.Load(dir); ok {
+		return root.(string)
+	}
+
+	root := ""
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			root = d
+			break
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+
+	moduleRoots.Store(dir, root)
+
+	return root
+}
+
+// shortFunction strips the import path from a fully qualified function name,
+// e.g. "github.com/a/b/pkg.(*T).Method" becomes "pkg.(*T).Method".
+func shortFunction(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		return function[i+1:]
+	}
+
+	return function
+}
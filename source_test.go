@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceValue(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &slog.Source{
+		Function: "gopkg.in/slog-handler.v1.(*Handler).Handle",
+		File:     filepath.Join(wd, "internal", "handler.go"),
+		Line:     42,
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		{
+			name: "default is parent dir and file",
+			mode: "",
+			want: "internal/handler.go:42",
+		},
+		{
+			name: "full path",
+			mode: "full",
+			want: src.File + ":42",
+		},
+		{
+			name: "relative to module root",
+			mode: "relative",
+			want: "internal/handler.go:42",
+		},
+		{
+			name: "base name",
+			mode: "base",
+			want: "handler.go:42",
+		},
+		{
+			name: "function name",
+			mode: "func",
+			want: "slog-handler.v1.(*Handler).Handle handler.go:42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sourceValue(tt.mode, false, src).String()
+			if got != tt.want {
+				t.Errorf("sourceValue(%q) = %q, want %q", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceValue_Struct(t *testing.T) {
+	src := &slog.Source{
+		Function: "main.main",
+		File:     "/src/app/main.go",
+		Line:     7,
+	}
+
+	v := sourceValue("base", true, src)
+	if v.Kind() != slog.KindGroup {
+		t.Fatalf("Kind() = %v, want group", v.Kind())
+	}
+
+	got := map[string]string{}
+	for _, a := range v.Group() {
+		got[a.Key] = a.Value.String()
+	}
+
+	want := map[string]string{
+		"file":     "main.go",
+		"line":     "7",
+		"function": "main.main",
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %q, want %q", k, got[k], w)
+		}
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "module cache",
+			path: "/home/u/go/pkg/mod/github.com/a/b@v1.0.0/c.go",
+			want: "github.com/a/b@v1.0.0/c.go",
+		},
+		{
+			name: "outside any module",
+			path: "/nonexistent/dir/file.go",
+			want: "/nonexistent/dir/file.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relativePath(tt.path); got != tt.want {
+				t.Errorf("relativePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
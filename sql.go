@@ -0,0 +1,448 @@
+package logger
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+)
+
+// SQLOptions configures the query logging of WrapDriver and WrapConnector.
+type SQLOptions struct {
+	Message       string        // Message is the message of query records (default "sql query")
+	Level         string        // Level is the level of successful queries: "debug", "info" (default), "warn" or "error"
+	SlowThreshold time.Duration // SlowThreshold logs queries taking at least this long at warn level
+	OnlyErrors    bool          // OnlyErrors logs failed queries only, and slow queries if SlowThreshold is set
+	LogArgs       bool          // LogArgs logs argument values instead of their type and length
+}
+
+// sqlLogger logs the queries of wrapped connections.
+type sqlLogger struct {
+	logger *slog.Logger
+	opts   SQLOptions
+	level  slog.Level
+	redact *redactor
+}
+
+// newSQLLogger returns a sqlLogger for l with defaults applied to opts.
+func newSQLLogger(l *slog.Logger, opts SQLOptions) *sqlLogger {
+	if opts.Message == "" {
+		opts.Message = "sql query"
+	}
+
+	return &sqlLogger{logger: l, opts: opts, level: ParseLevel(opts.Level), redact: loggerRedactor(l)}
+}
+
+// log emits a record for a query that started at start.
+// rows is the number of affected rows, or negative if unknown.
+func (s *sqlLogger) log(ctx context.Context, query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+	// ErrSkip makes database/sql retry the query another way, which is logged instead
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	duration := time.Since(start)
+	slow := s.opts.SlowThreshold > 0 && duration >= s.opts.SlowThreshold
+
+	level := s.level
+	switch {
+	case err != nil:
+		level = slog.LevelError
+	case slow:
+		level = slog.LevelWarn
+	case s.opts.OnlyErrors:
+		return
+	}
+
+	if !s.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", query),
+		slog.Duration("duration", duration),
+	}
+	if len(args) > 0 {
+		attrs = append(attrs, slog.Any("args", s.args(args)))
+	}
+	if rows >= 0 {
+		attrs = append(attrs, slog.Int64("rows_affected", rows))
+	}
+	if slow {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	s.logger.LogAttrs(ctx, level, s.opts.Message, attrs...)
+}
+
+// args returns the query arguments for logging. Unless LogArgs is set, values are
+// summarized by their type and length, as positional arguments carry no name telling
+// whether they are sensitive. Named arguments matching the redaction keys are redacted
+// and byte slices are summarized by their length in any case.
+func (s *sqlLogger) args(args []driver.NamedValue) []any {
+	values := make([]any, 0, len(args))
+
+	for _, a := range args {
+		v := a.Value
+
+		switch {
+		case a.Name != "" && s.redact.matchKey(a.Name):
+			if s.redact.policy == RedactDrop {
+				continue
+			}
+
+			v = s.redact.replace(fmt.Sprint(v))
+		case v == nil:
+		case !s.opts.LogArgs:
+			v = argSummary(v)
+		default:
+			if b, ok := v.([]byte); ok {
+				v = argSummary(b)
+			}
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// argSummary describes an argument value without revealing it, e.g. "[4 bytes]",
+// "[7 chars]" or "[int64]".
+func argSummary(v any) string {
+	switch v := v.(type) {
+	case []byte:
+		return fmt.Sprintf("[%d bytes]", len(v))
+	case string:
+		return fmt.Sprintf("[%d chars]", utf8.RuneCountInString(v))
+	default:
+		return fmt.Sprintf("[%T]", v)
+	}
+}
+
+// WrapDriver returns a driver.Driver logging the queries of connections opened by d to l.
+// Register it with sql.Register to use it with sql.Open.
+//
+// Queries are logged with their arguments, the number of affected rows, the duration
+// and the error, if any. Failed queries are logged at error level. Argument values are
+// only logged if opts has LogArgs set.
+func WrapDriver(l *slog.Logger, d driver.Driver, opts SQLOptions) driver.Driver {
+	return &sqlDriver{Driver: d, log: newSQLLogger(l, opts)}
+}
+
+// WrapConnector returns a driver.Connector logging the queries of connections made by c to l,
+// for use with sql.OpenDB. Queries are logged as described for WrapDriver.
+func WrapConnector(l *slog.Logger, c driver.Connector, opts SQLOptions) driver.Connector {
+	log := newSQLLogger(l, opts)
+
+	return &sqlConnector{Connector: c, driver: &sqlDriver{Driver: c.Driver(), log: log}, log: log}
+}
+
+// sqlDriver wraps a driver.Driver.
+type sqlDriver struct {
+	driver.Driver
+
+	log *sqlLogger
+}
+
+// Open implements driver.Driver.
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{Conn: c, log: d.log}, nil
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &sqlConnector{Connector: c, driver: d, log: d.log}, nil
+	}
+
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+// sqlConnector wraps a driver.Connector.
+type sqlConnector struct {
+	driver.Connector
+
+	driver *sqlDriver
+	log    *sqlLogger
+}
+
+// Connect implements driver.Connector.
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{Conn: conn, log: c.log}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is the connector of drivers without driver.DriverContext.
+type dsnConnector struct {
+	name   string
+	driver *sqlDriver
+}
+
+// Connect implements driver.Connector.
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver implements driver.Connector.
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// sqlConn wraps a driver.Conn. Optional interfaces the wrapped connection does not
+// implement report driver.ErrSkip or their neutral result, so database/sql falls back
+// to its default behavior.
+type sqlConn struct {
+	driver.Conn
+
+	log *sqlLogger
+}
+
+// Prepare implements driver.Conn.
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext. Only failures are logged,
+// executions of the statement are logged separately.
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+
+	start := time.Now()
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+
+	if err != nil {
+		c.log.log(ctx, query, nil, start, -1, err)
+		return nil, err
+	}
+
+	return &sqlStmt{Stmt: stmt, conn: c.Conn, query: query, log: c.log}, nil
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support non-default transaction options")
+	}
+
+	return c.Conn.Begin()
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.log.log(ctx, query, args, start, rowsAffected(res), err)
+
+	return res, err
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	c.log.log(ctx, query, args, start, -1, err)
+
+	return rows, err
+}
+
+// Ping implements driver.Pinger.
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+// sqlStmt wraps a driver.Stmt prepared on conn.
+type sqlStmt struct {
+	driver.Stmt
+
+	conn  driver.Conn
+	query string
+	log   *sqlLogger
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var (
+		res driver.Result
+		err error
+	)
+
+	start := time.Now()
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			res, err = s.Stmt.Exec(values)
+		}
+	}
+
+	s.log.log(ctx, s.query, args, start, rowsAffected(res), err)
+
+	return res, err
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var (
+		rows driver.Rows
+		err  error
+	)
+
+	start := time.Now()
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+
+	s.log.log(ctx, s.query, args, start, -1, err)
+
+	return rows, err
+}
+
+// CheckNamedValue implements driver.NamedValueChecker. database/sql only uses the checker
+// of the connection if the statement has none, so the checker of the wrapped connection is
+// used if the wrapped statement does not implement one.
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	if nc, ok := s.conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+
+	if _, ok := s.Stmt.(driver.ColumnConverter); ok {
+		return driver.ErrSkip
+	}
+
+	// without a column converter, database/sql converts like DefaultParameterConverter
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+
+	nv.Value = v
+
+	return nil
+}
+
+// ColumnConverter implements driver.ColumnConverter. It is only used after CheckNamedValue
+// skipped an argument, i.e. if the wrapped statement has a column converter, or else
+// falls back to the default conversion like CheckNamedValue.
+func (s *sqlStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.Stmt.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+
+	return driver.DefaultParameterConverter
+}
+
+// namedValues converts arguments for drivers without context support, which accept positional arguments only.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+
+		values[i] = a.Value
+	}
+
+	return values, nil
+}
+
+// rowsAffected returns the number of rows affected by res, or -1 if unknown.
+func rowsAffected(res driver.Result) int64 {
+	if res == nil {
+		return -1
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
@@ -0,0 +1,361 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDriver is an in-memory driver. Queries containing "fail" return an error,
+// queries containing "slow" take 5ms and exec queries affect 3 rows.
+type fakeDriver struct {
+	execer  bool // execer makes connections implement driver.ExecerContext
+	checker bool // checker makes connections implement driver.NamedValueChecker
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	switch {
+	case d.execer:
+		return fakeExecerConn{}, nil
+	case d.checker:
+		return fakeCheckerConn{}, nil
+	}
+
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "syntax error") {
+		return nil, errors.New("syntax error")
+	}
+
+	return fakeStmt{query: query}, nil
+}
+
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeExecerConn struct{ fakeConn }
+
+func (fakeExecerConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	return fakeStmt{query: query}.Exec(nil)
+}
+
+// fakeCheckerConn accepts []string arguments, which database/sql rejects by default.
+type fakeCheckerConn struct{ fakeConn }
+
+func (fakeCheckerConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if _, ok := nv.Value.([]string); ok {
+		return nil
+	}
+
+	return driver.ErrSkip
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "slow") {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if strings.Contains(s.query, "fail") {
+		return nil, errors.New("constraint violation")
+	}
+
+	return driver.RowsAffected(3), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if _, err := s.Exec(args); err != nil {
+		return nil, err
+	}
+
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"id"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	dest[0] = int64(1)
+
+	return nil
+}
+
+type fakeConnector struct{ fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.fakeDriver }
+
+func TestWrapDriver(t *testing.T) {
+	tests := []struct {
+		name     string
+		logOpts  Options
+		opts     SQLOptions
+		driver   fakeDriver
+		run      func(db *sql.DB) error
+		validate func(t *testing.T, err error, lines []map[string]any)
+	}{
+		{
+			name: "exec through prepared statement",
+			opts: SQLOptions{LogArgs: true},
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "bob", 7)
+				return err
+			},
+			validate: func(t *testing.T, err error, lines []map[string]any) {
+				if err != nil {
+					t.Fatalf("Exec() error = %v", err)
+				}
+				if len(lines) != 1 {
+					t.Fatalf("got %d records, want 1", len(lines))
+				}
+				out := lines[0]
+				if out["level"] != "info" || out["msg"] != "sql query" || out["query"] != "UPDATE users SET name = ? WHERE id = ?" {
+					t.Errorf("out = %v, want info sql query", out)
+				}
+				if out["rows_affected"] != 3.0 {
+					t.Errorf("rows_affected = %v, want 3", out["rows_affected"])
+				}
+				if args, _ := out["args"].([]any); len(args) != 2 || args[0] != "bob" || args[1] != 7.0 {
+					t.Errorf("args = %v, want [bob 7]", out["args"])
+				}
+				if _, ok := out["duration"].(float64); !ok {
+					t.Errorf("duration = %v, want a number", out["duration"])
+				}
+			},
+		},
+		{
+			name:   "exec through ExecerContext",
+			driver: fakeDriver{execer: true},
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("DELETE FROM sessions")
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["rows_affected"] != 3.0 || lines[0]["args"] != nil {
+					t.Errorf("lines = %v, want one record without args", lines)
+				}
+			},
+		},
+		{
+			name: "query",
+			run: func(db *sql.DB) error {
+				var id int
+				return db.QueryRow("SELECT id FROM users WHERE email = ?", "bob@example.com").Scan(&id)
+			},
+			validate: func(t *testing.T, err error, lines []map[string]any) {
+				if err != nil {
+					t.Fatalf("QueryRow() error = %v", err)
+				}
+				if len(lines) != 1 || lines[0]["query"] != "SELECT id FROM users WHERE email = ?" {
+					t.Fatalf("lines = %v, want the query", lines)
+				}
+				if _, ok := lines[0]["rows_affected"]; ok {
+					t.Error("rows_affected should not be set for queries")
+				}
+			},
+		},
+		{
+			name: "failed query",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("INSERT fail")
+				return err
+			},
+			validate: func(t *testing.T, err error, lines []map[string]any) {
+				if err == nil {
+					t.Fatal("Exec() error = nil, want error")
+				}
+				if len(lines) != 1 || lines[0]["level"] != "error" || lines[0]["error"] != "constraint violation" {
+					t.Errorf("lines = %v, want one error record", lines)
+				}
+			},
+		},
+		{
+			name: "failed prepare",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("syntax error")
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["level"] != "error" || lines[0]["query"] != "syntax error" {
+					t.Errorf("lines = %v, want one error record", lines)
+				}
+			},
+		},
+		{
+			name: "slow query",
+			opts: SQLOptions{SlowThreshold: time.Millisecond},
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("SELECT slow")
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["level"] != "warn" || lines[0]["slow"] != true {
+					t.Errorf("lines = %v, want one slow warn record", lines)
+				}
+			},
+		},
+		{
+			name: "only errors",
+			opts: SQLOptions{OnlyErrors: true},
+			run: func(db *sql.DB) error {
+				_, _ = db.Exec("SELECT 1")
+				_, err := db.Exec("INSERT fail")
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				if len(lines) != 1 || lines[0]["query"] != "INSERT fail" {
+					t.Errorf("lines = %v, want only the failed query", lines)
+				}
+			},
+		},
+		{
+			name: "configured level",
+			opts: SQLOptions{Level: "debug"},
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("SELECT 1")
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				if len(lines) != 0 {
+					t.Errorf("lines = %v, want debug records filtered", lines)
+				}
+			},
+		},
+		{
+			name: "positional args are summarized",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("UPDATE users SET password = ? WHERE id = ? AND deleted = ?", "hunter2", 7, nil)
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				args, _ := lines[0]["args"].([]any)
+				if len(args) != 3 || args[0] != "[7 chars]" || args[1] != "[int64]" || args[2] != nil {
+					t.Errorf("args = %v, want values summarized", lines[0]["args"])
+				}
+			},
+		},
+		{
+			name:   "connection checker through prepare fallback",
+			driver: fakeDriver{checker: true},
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("DELETE FROM users WHERE name = ANY(?) AND id = ?", []string{"bob", "eve"}, 7)
+				return err
+			},
+			validate: func(t *testing.T, err error, lines []map[string]any) {
+				if err != nil {
+					t.Fatalf("Exec() error = %v", err)
+				}
+				if args, _ := lines[0]["args"].([]any); len(args) != 2 || args[0] != "[[]string]" || args[1] != "[int64]" {
+					t.Errorf("args = %v, want the checked and the converted argument", lines[0]["args"])
+				}
+			},
+		},
+		{
+			name:   "connection checker through prepared statement",
+			driver: fakeDriver{checker: true},
+			run: func(db *sql.DB) error {
+				stmt, err := db.Prepare("DELETE FROM users WHERE name = ANY(?)")
+				if err != nil {
+					return err
+				}
+				defer stmt.Close()
+
+				_, err = stmt.Exec([]string{"bob"})
+				return err
+			},
+			validate: func(t *testing.T, err error, lines []map[string]any) {
+				if err != nil {
+					t.Fatalf("Exec() error = %v", err)
+				}
+				if len(lines) != 1 {
+					t.Errorf("lines = %v, want one record", lines)
+				}
+			},
+		},
+		{
+			name: "redacted args",
+			opts: SQLOptions{LogArgs: true},
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("UPDATE users SET password = :password WHERE id = :id",
+					sql.Named("password", "hunter2"), sql.Named("id", 7), []byte("blob"))
+				return err
+			},
+			validate: func(t *testing.T, _ error, lines []map[string]any) {
+				args, _ := lines[0]["args"].([]any)
+				if len(args) != 3 || args[0] != "[REDACTED]" || args[1] != 7.0 || args[2] != "[4 bytes]" {
+					t.Errorf("args = %v, want password redacted", lines[0]["args"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if tt.logOpts.Format == "" {
+				tt.logOpts.Format = "json"
+			}
+
+			connector, err := WrapDriver(newLogger(&buf, tt.logOpts), tt.driver, tt.opts).(driver.DriverContext).OpenConnector("")
+			if err != nil {
+				t.Fatalf("OpenConnector() error = %v", err)
+			}
+
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			err = tt.run(db)
+
+			tt.validate(t, err, decodeLines(t, buf.String()))
+		})
+	}
+}
+
+func TestWrapConnector(t *testing.T) {
+	var buf bytes.Buffer
+
+	db := sql.OpenDB(WrapConnector(newLogger(&buf, Options{Format: "json"}), fakeConnector{}, SQLOptions{}))
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := tx.Exec("UPDATE accounts SET balance = 0"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	lines := decodeLines(t, buf.String())
+	if len(lines) != 1 || lines[0]["query"] != "UPDATE accounts SET balance = 0" {
+		t.Errorf("lines = %v, want the query of the transaction", lines)
+	}
+}
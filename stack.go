@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// defaultStackDepth is the number of frames captured when Options.StackDepth is not set.
+const defaultStackDepth = 32
+
+// Frame describes a single call-stack entry attached to error-level records.
+type Frame struct {
+	Function string `json:"func"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// callerFrames captures the call-stack of the current goroutine.
+// If pc is non-zero and found on the stack, frames above it are dropped so that the
+// stack starts at the call site of the record. Otherwise the stack starts at the caller
+// of the function that invoked callerFrames (skip counts as in runtime.Callers).
+// If filter is true, runtime and log/slog frames are omitted.
+func callerFrames(skip int, pc uintptr, depth int, filter bool) []Frame {
+	if depth < 1 {
+		depth = defaultStackDepth
+	}
+
+	// capture enough frames to locate pc and still fill depth
+	pcs := make([]uintptr, depth+64)
+	pcs = pcs[:runtime.Callers(skip+1, pcs)]
+
+	if pc != 0 {
+		for i := range pcs {
+			if pcs[i] == pc {
+				pcs = pcs[i:]
+				break
+			}
+		}
+	}
+
+	var (
+		frames = make([]Frame, 0, depth)
+		it     = runtime.CallersFrames(pcs)
+	)
+
+	for len(frames) < depth {
+		f, more := it.Next()
+
+		if !filter || !isRuntimeFrame(f.Function) {
+			frames = append(frames, Frame{
+				Function: f.Function,
+				File:     f.File,
+				Line:     f.Line,
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return frames
+}
+
+// hasStack reports whether the record already carries a "stack" attribute,
+// such as the stack of a recovered panic.
+func hasStack(r slog.Record) bool {
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == "stack"
+		return !found
+	})
+
+	return found
+}
+
+// isRuntimeFrame reports whether the function belongs to the Go runtime or log/slog.
+func isRuntimeFrame(function string) bool {
+	return strings.HasPrefix(function, "runtime.") ||
+		strings.HasPrefix(function, "log/slog.")
+}
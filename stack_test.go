@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Stack(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		logFunc  func(logger *slog.Logger)
+		validate func(t *testing.T, stack []Frame)
+	}{
+		{
+			name: "disabled by default",
+			opts: Options{
+				Format: "json",
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Error("failure")
+			},
+			validate: func(t *testing.T, stack []Frame) {
+				if stack != nil {
+					t.Errorf("stack = %v, want none", stack)
+				}
+			},
+		},
+		{
+			name: "not attached below error level",
+			opts: Options{
+				Format: "json",
+				Stack:  "caller",
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Warn("warning")
+			},
+			validate: func(t *testing.T, stack []Frame) {
+				if stack != nil {
+					t.Errorf("stack = %v, want none", stack)
+				}
+			},
+		},
+		{
+			name: "caller stack starts at call site",
+			opts: Options{
+				Format: "json",
+				Stack:  "caller",
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Error("failure")
+			},
+			validate: func(t *testing.T, stack []Frame) {
+				if len(stack) == 0 {
+					t.Fatal("stack should not be empty")
+				}
+				if !strings.Contains(stack[0].Function, "TestHandler_Stack") {
+					t.Errorf("first frame = %q, want the logging function", stack[0].Function)
+				}
+				if !strings.HasSuffix(stack[0].File, "stack_test.go") {
+					t.Errorf("first frame file = %q, want stack_test.go", stack[0].File)
+				}
+			},
+		},
+		{
+			name: "goroutine stack is filtered",
+			opts: Options{
+				Format:      "json",
+				Stack:       "goroutine",
+				StackFilter: true,
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Error("failure")
+			},
+			validate: func(t *testing.T, stack []Frame) {
+				if len(stack) == 0 {
+					t.Fatal("stack should not be empty")
+				}
+				for _, f := range stack {
+					if isRuntimeFrame(f.Function) {
+						t.Errorf("unexpected frame %q", f.Function)
+					}
+				}
+			},
+		},
+		{
+			name: "depth is limited",
+			opts: Options{
+				Format:     "json",
+				Stack:      "goroutine",
+				StackDepth: 2,
+			},
+			logFunc: func(logger *slog.Logger) {
+				logger.Error("failure")
+			},
+			validate: func(t *testing.T, stack []Frame) {
+				if len(stack) != 2 {
+					t.Errorf("len(stack) = %d, want 2", len(stack))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			handler := NewHandler(&buf, &tt.opts)
+			tt.logFunc(slog.New(&handler))
+
+			var out struct {
+				Stack []Frame `json:"stack"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			tt.validate(t, out.Stack)
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslogFacilities maps facility names to their RFC 5424 codes.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSockets are the paths of the local syslog daemon tried if SyslogOptions.Network is empty.
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// DefaultSDID is the structured data ID of attributes if SyslogOptions.SDID is empty.
+// 32473 is the private enterprise number reserved for documentation by RFC 5612.
+const DefaultSDID = "slog@32473"
+
+// SyslogOptions configures NewSyslogWriter and NewSyslogHandler.
+type SyslogOptions struct {
+	Network string // Network is "udp", "tcp", "unix" or "unixgram"; empty connects to the local syslog daemon
+	Address string // Address is the host:port or socket path of the syslog daemon
+
+	Format string // Format selects the protocol: "rfc5424" (default) or "rfc3164"
+	Body   string // Body selects how attributes are encoded: "sd" as RFC 5424 structured data (default) or "json"
+
+	Facility string // Facility is the facility name, e.g. "daemon" or "local0" (default "user")
+	Hostname string // Hostname is the HOSTNAME field (default os.Hostname)
+	AppName  string // AppName is the APP-NAME field, or the tag in RFC 3164 (default the executable name)
+	ProcID   string // ProcID is the PROCID field (default the process ID)
+	MsgID    string // MsgID is the MSGID field of RFC 5424 (default "-")
+	SDID     string // SDID is the structured data ID of attributes (default DefaultSDID)
+}
+
+// SyslogWriter sends the JSON records written by a Handler to a syslog daemon.
+// Each Write must contain a single record. Levels are mapped to severities: debug,
+// info, notice (between info and warn), warning, err, crit (panic) and alert (fatal).
+//
+// Datagram transports send one message per packet, stream transports frame messages
+// by octet counting as described in RFC 6587. If a write fails, the connection is
+// re-established and the write is retried once.
+type SyslogWriter struct {
+	opts     SyslogOptions
+	facility int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogWriter connects to the syslog daemon described by opts.
+func NewSyslogWriter(opts SyslogOptions) (*SyslogWriter, error) {
+	if opts.Format != "rfc3164" {
+		opts.Format = "rfc5424"
+	}
+	if opts.Body != "json" {
+		opts.Body = "sd"
+	}
+	if _, ok := syslogFacilities[opts.Facility]; !ok {
+		opts.Facility = "user"
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.ProcID == "" {
+		opts.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if opts.SDID == "" {
+		opts.SDID = DefaultSDID
+	}
+
+	w := &SyslogWriter{opts: opts, facility: syslogFacilities[opts.Facility]}
+
+	conn, err := w.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	w.conn = conn
+
+	return w, nil
+}
+
+// NewSyslogHandler returns a handler sending records to the syslog daemon described by sopts.
+// Records are processed like those of NewLogger with opts, including redaction, limits
+// and context attributes. Closing the handler closes the connection.
+func NewSyslogHandler(opts Options, sopts SyslogOptions) (CloseHandler, error) {
+	if opts.Null {
+		return NewNullHandler(), nil
+	}
+
+	w, err := NewSyslogWriter(sopts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Format = "json"
+	opts.Pretty = false
+
+	return newHandler(w, opts).(CloseHandler), nil
+}
+
+// Write sends the record in p, reconnecting once if the connection fails.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	msg := w.format(p, time.Now())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if err := w.send(msg); err == nil {
+			return len(p), nil
+		}
+
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	conn, err := w.dial()
+	if err != nil {
+		return 0, err
+	}
+
+	w.conn = conn
+
+	if err := w.send(msg); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to the syslog daemon.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
+
+// dial connects to the syslog daemon.
+func (w *SyslogWriter) dial() (net.Conn, error) {
+	if w.opts.Network != "" {
+		conn, err := net.Dial(w.opts.Network, w.opts.Address)
+		if err != nil {
+			return nil, fmt.Errorf("syslog: %w", err)
+		}
+
+		return conn, nil
+	}
+
+	for _, path := range syslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				return conn, nil
+			}
+		}
+	}
+
+	return nil, errors.New("syslog: no local syslog daemon found")
+}
+
+// send writes a message to the connection, framed for stream transports.
+func (w *SyslogWriter) send(msg []byte) error {
+	switch w.conn.LocalAddr().Network() {
+	case "udp", "unixgram":
+	default:
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	_, err := w.conn.Write(msg)
+
+	return err
+}
+
+// format converts a JSON record into a syslog message, timestamped with the time of
+// the record, or now if it has none in a known layout. The timestamp has a precision of
+// seconds, like the time written by Handler. Writes that are not JSON objects are sent
+// as the message text at info level.
+func (w *SyslogWriter) format(p []byte, now time.Time) []byte {
+	line := bytes.TrimSpace(p)
+
+	var attrs map[string]any
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&attrs); err != nil {
+		attrs = map[string]any{slog.MessageKey: string(line)}
+	}
+
+	level, _ := attrs[slog.LevelKey].(string)
+	msg, _ := attrs[slog.MessageKey].(string)
+
+	// Handler writes the time in local time without a zone, replacers may use RFC 3339
+	if s, ok := attrs[slog.TimeKey].(string); ok {
+		if t, err := time.ParseInLocation(time.DateTime, s, time.Local); err == nil {
+			now = t
+		} else if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			now = t
+		}
+	}
+
+	for _, k := range []string{slog.LevelKey, slog.MessageKey, slog.TimeKey} {
+		delete(attrs, k)
+	}
+
+	if w.opts.Body == "json" {
+		msg = string(line)
+	}
+
+	pri := w.facility*8 + syslogSeverity(level)
+
+	var b bytes.Buffer
+	if w.opts.Format == "rfc3164" {
+		if w.opts.Body == "sd" && len(attrs) > 0 {
+			if j, err := json.Marshal(attrs); err == nil {
+				msg += " " + string(j)
+			}
+		}
+
+		fmt.Fprintf(&b, "<%d>%s %s %s[%s]: %s", pri, now.Format(time.Stamp),
+			syslogField(w.opts.Hostname, 255), syslogField(w.opts.AppName, 32), w.opts.ProcID, msg)
+
+		return b.Bytes()
+	}
+
+	sd := "-"
+	if w.opts.Body == "sd" && len(attrs) > 0 {
+		sd = structuredData(w.opts.SDID, attrs)
+	}
+
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s %s", pri, now.Format(time.RFC3339),
+		syslogField(w.opts.Hostname, 255), syslogField(w.opts.AppName, 48),
+		syslogField(w.opts.ProcID, 128), syslogField(w.opts.MsgID, 32), sd)
+
+	if msg != "" {
+		b.WriteString(" " + msg)
+	}
+
+	return b.Bytes()
+}
+
+// syslogSeverity maps a level name as written by Handler to a syslog severity.
+func syslogSeverity(name string) int {
+	var level slog.Level
+
+	switch strings.ToLower(name) {
+	case "panic":
+		level = LevelPanic
+	case "fatal":
+		level = LevelFatal
+	default:
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			level = slog.LevelInfo
+		}
+	}
+
+	switch {
+	case level >= LevelFatal:
+		return 1 // alert
+	case level >= LevelPanic:
+		return 2 // crit
+	case level >= slog.LevelError:
+		return 3 // err
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level > slog.LevelInfo:
+		return 5 // notice
+	case level >= slog.LevelInfo:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// syslogField returns s as an RFC 5424 header field: printable US-ASCII without spaces,
+// at most n characters, or "-" if empty.
+func syslogField(s string, n int) string {
+	if s == "" {
+		return "-"
+	}
+
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+
+	if len(b) > n {
+		b = b[:n]
+	}
+
+	return string(b)
+}
+
+// structuredData encodes attrs as a single SD-ELEMENT with the given ID.
+// Groups are flattened into dotted parameter names.
+func structuredData(id string, attrs map[string]any) string {
+	params := map[string]string{}
+	flattenParams("", attrs, params)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("[" + sdName(id, len(id)))
+
+	for _, name := range names {
+		b.WriteString(" " + name + `="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(params[name]))
+		b.WriteString(`"`)
+	}
+
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// flattenParams adds the values of m to params, prefixing names of nested groups.
+func flattenParams(prefix string, m map[string]any, params map[string]string) {
+	for k, v := range m {
+		name := sdName(prefix+k, 32)
+
+		switch v := v.(type) {
+		case map[string]any:
+			flattenParams(prefix+k+".", v, params)
+		case string:
+			params[name] = v
+		case nil:
+			params[name] = ""
+		case json.Number:
+			params[name] = v.String()
+		case bool:
+			params[name] = strconv.FormatBool(v)
+		default:
+			j, _ := json.Marshal(v)
+			params[name] = string(j)
+		}
+	}
+}
+
+// sdName returns s as an SD-NAME: printable US-ASCII except '=', ' ', ']' and '"',
+// at most n characters.
+func sdName(s string, n int) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+
+	if len(b) > n {
+		b = b[:n]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,249 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter_format(t *testing.T) {
+	// records without a time in a known layout are sent with now, others with the time they were logged at
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := `{"time":"2023-07-08 09:10:11","level":"warn","msg":"disk low","free":12,"dir":"/var","req":{"id":"a]b"}}` + "\n"
+	logged := time.Date(2023, 7, 8, 9, 10, 11, 0, time.Local).Format(time.RFC3339)
+
+	tests := []struct {
+		name   string
+		opts   SyslogOptions
+		record string
+		want   string
+	}{
+		{
+			name:   "rfc5424 structured data",
+			opts:   SyslogOptions{Format: "rfc5424", Body: "sd", Facility: "user"},
+			record: record,
+			want:   `<12>1 ` + logged + ` host app 42 - [slog@32473 dir="/var" free="12" req.id="a\]b"] disk low`,
+		},
+		{
+			name:   "rfc5424 json body",
+			opts:   SyslogOptions{Format: "rfc5424", Body: "json", Facility: "local0", MsgID: "audit"},
+			record: record,
+			want:   `<132>1 ` + logged + ` host app 42 audit - ` + strings.TrimSpace(record),
+		},
+		{
+			name:   "rfc5424 without attributes",
+			opts:   SyslogOptions{Format: "rfc5424", Body: "sd", Facility: "daemon"},
+			record: `{"level":"error","msg":"failed"}`,
+			want:   `<27>1 2024-01-02T03:04:05Z host app 42 - - failed`,
+		},
+		{
+			name:   "rfc3339 time",
+			opts:   SyslogOptions{Format: "rfc5424", Body: "sd", Facility: "user"},
+			record: `{"time":"2023-07-08T09:10:11.123456+02:00","level":"info","msg":"replaced"}`,
+			want:   `<14>1 2023-07-08T09:10:11+02:00 host app 42 - - replaced`,
+		},
+		{
+			name:   "unknown time layout",
+			opts:   SyslogOptions{Format: "rfc5424", Body: "sd", Facility: "user"},
+			record: `{"time":"1688807411","level":"info","msg":"replaced"}`,
+			want:   `<14>1 2024-01-02T03:04:05Z host app 42 - - replaced`,
+		},
+		{
+			name:   "rfc3164",
+			opts:   SyslogOptions{Format: "rfc3164", Body: "sd", Facility: "user"},
+			record: `{"level":"info","msg":"started","port":8080}`,
+			want:   `<14>Jan  2 03:04:05 host app[42]: started {"port":8080}`,
+		},
+		{
+			name:   "plain text",
+			opts:   SyslogOptions{Format: "rfc5424", Body: "sd", Facility: "user"},
+			record: "not json\n",
+			want:   `<14>1 2024-01-02T03:04:05Z host app 42 - - not json`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Hostname, opts.AppName, opts.ProcID, opts.SDID = "host", "app", "42", DefaultSDID
+
+			w := &SyslogWriter{opts: opts, facility: syslogFacilities[opts.Facility]}
+
+			if got := string(w.format([]byte(tt.record), now)); got != tt.want {
+				t.Errorf("format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	tests := []struct {
+		level string
+		want  int
+	}{
+		{"debug", 7},
+		{"info", 6},
+		{"INFO+2", 5},
+		{"warn", 4},
+		{"error", 3},
+		{"panic", 2},
+		{"fatal", 1},
+		{"unknown", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			if got := syslogSeverity(tt.level); got != tt.want {
+				t.Errorf("syslogSeverity(%q) = %d, want %d", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSyslogHandler_udp(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	handler, err := NewSyslogHandler(Options{Level: "debug", Redact: &Redaction{Keys: []string{"password"}}}, SyslogOptions{
+		Network: "udp", Address: pc.LocalAddr().String(), AppName: "app", Facility: "local3",
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+	defer handler.Close(context.Background())
+
+	slog.New(handler).Debug("probe", "password", "secret", "n", 1)
+
+	got := readPacket(t, pc)
+
+	for _, want := range []string{"<159>1 ", " app " + strconv.Itoa(os.Getpid()) + " - ", `n="1"`, `password="[REDACTED\]"`, "] probe"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestNewSyslogHandler_tcp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	handler, err := NewSyslogHandler(Options{}, SyslogOptions{Network: "tcp", Address: ln.Addr().String(), Body: "json"})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+	defer handler.Close(context.Background())
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger := slog.New(handler)
+	logger.Info("first")
+	logger.Error("second")
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{`<14>1 `, `<11>1 `} {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		size, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			t.Fatalf("frame length %q: %v", size, err)
+		}
+
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(string(msg), want) || !strings.HasSuffix(string(msg), "}") {
+			t.Errorf("message = %q, want prefix %q and a JSON body", msg, want)
+		}
+	}
+}
+
+func TestSyslogWriter_reconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+
+	listen := func() net.PacketConn {
+		pc, err := net.ListenPacket("unixgram", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pc
+	}
+
+	pc := listen()
+
+	w, err := NewSyslogWriter(SyslogOptions{Network: "unixgram", Address: path})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte(`{"level":"info","msg":"before"}`)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := readPacket(t, pc); !strings.HasSuffix(got, " before") {
+		t.Errorf("message = %q, want before", got)
+	}
+
+	// restart the daemon
+	pc.Close()
+	os.Remove(path)
+	pc = listen()
+	defer pc.Close()
+
+	if _, err := w.Write([]byte(`{"level":"info","msg":"after"}`)); err != nil {
+		t.Fatalf("Write() after restart error = %v", err)
+	}
+	if got := readPacket(t, pc); !strings.HasSuffix(got, " after") {
+		t.Errorf("message = %q, want after", got)
+	}
+}
+
+// readPacket reads a single datagram from pc.
+func readPacket(t *testing.T, pc net.PacketConn) string {
+	t.Helper()
+
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf := make([]byte, 64*1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+
+	return string(buf[:n])
+}
+
+func TestNewSyslogHandler_null(t *testing.T) {
+	handler, err := NewSyslogHandler(Options{Null: true}, SyslogOptions{Network: "udp", Address: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+
+	if err := handler.Close(context.Background()); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTraceparent is returned by ParseTraceparent for malformed traceparent values.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Trace identifies the active span of a context, following W3C Trace Context.
+type Trace struct {
+	TraceID string // TraceID is the 32-digit lowercase hex trace ID
+	SpanID  string // SpanID is the 16-digit lowercase hex span ID
+	Flags   byte   // Flags holds the trace flags; bit 0 is the sampled flag
+}
+
+// Sampled reports whether the sampled flag is set.
+func (t Trace) Sampled() bool {
+	return t.Flags&1 == 1
+}
+
+// TraceSource provides the trace of a context. It lets callers correlate logs with their
+// tracing library without a dependency on it, e.g. for OpenTelemetry:
+//
+//	logger.TraceSourceFunc(func(ctx context.Context) (logger.Trace, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return logger.Trace{
+//			TraceID: sc.TraceID().String(),
+//			SpanID:  sc.SpanID().String(),
+//			Flags:   byte(sc.TraceFlags()),
+//		}, sc.IsValid()
+//	})
+type TraceSource interface {
+	Trace(ctx context.Context) (Trace, bool)
+}
+
+// TraceSourceFunc adapts a function to the TraceSource interface.
+type TraceSourceFunc func(ctx context.Context) (Trace, bool)
+
+// Trace implements TraceSource.
+func (f TraceSourceFunc) Trace(ctx context.Context) (Trace, bool) {
+	return f(ctx)
+}
+
+// traceKey is the context key for traces stored by WithTraceparent.
+type traceKey struct{}
+
+// WithTraceparent returns a copy of ctx carrying the trace of a W3C traceparent header value.
+// If the value is malformed, ctx is returned unchanged.
+func WithTraceparent(ctx context.Context, traceparent string) context.Context {
+	t, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace stored in ctx by WithTraceparent.
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceKey{}).(Trace)
+	return t, ok
+}
+
+// ParseTraceparent parses a W3C traceparent value such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceparent(s string) (Trace, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return Trace{}, ErrInvalidTraceparent
+	}
+
+	// version ff is forbidden, version 00 has exactly four fields
+	if parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return Trace{}, ErrInvalidTraceparent
+	}
+
+	for _, p := range parts[:4] {
+		if !isLowerHex(p) {
+			return Trace{}, ErrInvalidTraceparent
+		}
+	}
+
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return Trace{}, ErrInvalidTraceparent
+	}
+
+	flags, _ := hex.DecodeString(parts[3])
+
+	return Trace{TraceID: parts[1], SpanID: parts[2], Flags: flags[0]}, nil
+}
+
+// traceExtractor returns an extractor emitting the trace of a context, taken from source
+// or, failing that, from a traceparent stored with WithTraceparent.
+// The format selects the key convention: "otel" (default), "datadog" or "gcp".
+// For "gcp", project is used to build the fully qualified trace name.
+func traceExtractor(source TraceSource, format, project string) Extractor {
+	return func(ctx context.Context) []slog.Attr {
+		t, ok := Trace{}, false
+		if source != nil {
+			t, ok = source.Trace(ctx)
+		}
+		if !ok {
+			if t, ok = TraceFromContext(ctx); !ok {
+				return nil
+			}
+		}
+
+		switch format {
+		case "datadog":
+			return []slog.Attr{
+				slog.Group("dd",
+					slog.String("trace_id", lowerDecimal(t.TraceID)),
+					slog.String("span_id", lowerDecimal(t.SpanID)),
+				),
+			}
+		case "gcp":
+			trace := t.TraceID
+			if project != "" {
+				trace = fmt.Sprintf("projects/%s/traces/%s", project, t.TraceID)
+			}
+
+			return []slog.Attr{
+				slog.String("logging.googleapis.com/trace", trace),
+				slog.String("logging.googleapis.com/spanId", t.SpanID),
+				slog.Bool("logging.googleapis.com/trace_sampled", t.Sampled()),
+			}
+		default:
+			return []slog.Attr{
+				slog.String("trace_id", t.TraceID),
+				slog.String("span_id", t.SpanID),
+				slog.String("trace_flags", fmt.Sprintf("%02x", t.Flags)),
+			}
+		}
+	}
+}
+
+// lowerDecimal converts the lower 64 bits of a hex ID to decimal, as used by Datadog.
+func lowerDecimal(id string) string {
+	if len(id) > 16 {
+		id = id[len(id)-16:]
+	}
+
+	n, err := strconv.ParseUint(id, 16, 64)
+	if err != nil {
+		return id
+	}
+
+	return strconv.FormatUint(n, 10)
+}
+
+// isLowerHex reports whether s consists of lowercase hex digits only.
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
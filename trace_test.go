@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Trace
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			value: testTraceparent,
+			want: Trace{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Flags:   1,
+			},
+		},
+		{
+			name:  "future version with extra fields",
+			value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra",
+			want: Trace{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+			},
+		},
+		{
+			name:    "version 00 with extra fields",
+			value:   testTraceparent + "-extra",
+			wantErr: true,
+		},
+		{
+			name:    "forbidden version",
+			value:   "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "zero trace id",
+			value:   "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "uppercase hex",
+			value:   "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			value:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTraceparent(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceparent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTraceparent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_Trace(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		ctx      context.Context
+		validate func(t *testing.T, out map[string]any)
+	}{
+		{
+			name: "no trace in context",
+			opts: Options{Format: "json"},
+			ctx:  context.Background(),
+			validate: func(t *testing.T, out map[string]any) {
+				if _, ok := out["trace_id"]; ok {
+					t.Error("trace_id should not be set")
+				}
+			},
+		},
+		{
+			name: "otel keys from traceparent",
+			opts: Options{Format: "json"},
+			ctx:  WithTraceparent(context.Background(), testTraceparent),
+			validate: func(t *testing.T, out map[string]any) {
+				if out["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+					t.Errorf("trace_id = %v", out["trace_id"])
+				}
+				if out["span_id"] != "00f067aa0ba902b7" {
+					t.Errorf("span_id = %v", out["span_id"])
+				}
+				if out["trace_flags"] != "01" {
+					t.Errorf("trace_flags = %v", out["trace_flags"])
+				}
+			},
+		},
+		{
+			name: "datadog keys",
+			opts: Options{Format: "json", TraceFormat: "datadog"},
+			ctx:  WithTraceparent(context.Background(), testTraceparent),
+			validate: func(t *testing.T, out map[string]any) {
+				dd, _ := out["dd"].(map[string]any)
+				if dd["trace_id"] != "11803532876627986230" {
+					t.Errorf("dd.trace_id = %v", dd["trace_id"])
+				}
+				if dd["span_id"] != "67667974448284343" {
+					t.Errorf("dd.span_id = %v", dd["span_id"])
+				}
+			},
+		},
+		{
+			name: "gcp keys",
+			opts: Options{Format: "json", TraceFormat: "gcp", TraceProject: "my-project"},
+			ctx:  WithTraceparent(context.Background(), testTraceparent),
+			validate: func(t *testing.T, out map[string]any) {
+				if out["logging.googleapis.com/trace"] != "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736" {
+					t.Errorf("trace = %v", out["logging.googleapis.com/trace"])
+				}
+				if out["logging.googleapis.com/trace_sampled"] != true {
+					t.Errorf("trace_sampled = %v", out["logging.googleapis.com/trace_sampled"])
+				}
+			},
+		},
+		{
+			name: "trace source takes precedence",
+			opts: Options{
+				Format: "json",
+				TraceSource: TraceSourceFunc(func(context.Context) (Trace, bool) {
+					return Trace{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331"}, true
+				}),
+			},
+			ctx: WithTraceparent(context.Background(), testTraceparent),
+			validate: func(t *testing.T, out map[string]any) {
+				if out["trace_id"] != "0af7651916cd43dd8448eb211c80319c" {
+					t.Errorf("trace_id = %v", out["trace_id"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			newLogger(&buf, tt.opts).InfoContext(tt.ctx, "test")
+
+			out := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("invalid output %q: %v", buf.String(), err)
+			}
+
+			tt.validate(t, out)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBodySize is the number of body bytes dumped if TransportOptions.MaxBodySize is zero.
+const DefaultMaxBodySize = 4096
+
+// sensitiveHeaders are always redacted in header dumps, in canonical form.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// TransportOptions configures the outbound request logging of Transport.
+type TransportOptions struct {
+	Message     string // Message is the message of request records (default "http client request")
+	DumpHeaders bool   // DumpHeaders logs request and response headers at debug level
+	DumpBody    bool   // DumpBody logs request and response bodies at debug level, once the response body is read or closed
+	MaxBodySize int    // MaxBodySize caps dumped bodies in bytes (default 4096)
+}
+
+// Transport is an http.RoundTripper logging outbound requests.
+type Transport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+	opts   TransportOptions
+	redact *redactor
+}
+
+// NewTransport returns a Transport that logs every request sent through next,
+// or http.DefaultTransport if next is nil. The record holds the method, URL, status,
+// duration, retry count and error, and is logged at error level for failed requests
+// and 5xx responses, warn level for 4xx and info level otherwise.
+//
+// Query parameters and dumped headers matching the redaction keys of l's Handler,
+// or DefaultRedactKeys if it has none, are redacted. Authorization and Cookie headers
+// are always redacted, as is the password of the URL.
+func NewTransport(l *slog.Logger, next http.RoundTripper, opts TransportOptions) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if opts.Message == "" {
+		opts.Message = "http client request"
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return &Transport{next: next, logger: l, opts: opts, redact: loggerRedactor(l)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	dump := (t.opts.DumpHeaders || t.opts.DumpBody) && t.logger.Enabled(ctx, slog.LevelDebug)
+
+	var dumped []slog.Attr
+
+	if dump && t.opts.DumpHeaders {
+		dumped = append(dumped, slog.Attr{Key: "request_headers", Value: t.headers(req.Header)})
+	}
+	if dump && t.opts.DumpBody && req.Body != nil && req.Body != http.NoBody {
+		body, rc, err := peekBody(req.Body, t.opts.MaxBodySize)
+		if err != nil {
+			req.Body.Close()
+			return nil, err
+		}
+
+		req = req.Clone(ctx)
+		req.Body = rc
+
+		dumped = append(dumped, slog.String("request_body", body))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", t.redactURL(req.URL)),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("retry", RetryCount(ctx)),
+	}
+
+	level := slog.LevelError
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	} else {
+		level = statusLevel(resp.StatusCode)
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+		if dump && t.opts.DumpHeaders {
+			dumped = append(dumped, slog.Attr{Key: "response_headers", Value: t.headers(resp.Header)})
+		}
+	}
+
+	t.logger.LogAttrs(ctx, level, t.opts.Message, attrs...)
+
+	// the response body is dumped once it has been read, so that streaming responses are not held back
+	if dump && t.opts.DumpBody && err == nil && resp.Body != nil && resp.Body != http.NoBody {
+		dumped = append(attrs[:2:2], dumped...)
+
+		resp.Body = &dumpBody{ReadCloser: resp.Body, max: t.opts.MaxBodySize, log: func(body string) {
+			t.logger.LogAttrs(ctx, slog.LevelDebug, t.opts.Message+" dump", append(dumped, slog.String("response_body", body))...)
+		}}
+	} else if len(dumped) > 0 {
+		t.logger.LogAttrs(ctx, slog.LevelDebug, t.opts.Message+" dump", append(attrs[:2:2], dumped...)...)
+	}
+
+	return resp, err
+}
+
+// redactURL returns u as a string with sensitive query parameters and the password redacted.
+func (t *Transport) redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	u2 := *u
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k, vs := range q {
+			if !t.redact.matchKey(k) {
+				continue
+			}
+
+			if t.redact.policy == RedactDrop {
+				q.Del(k)
+				continue
+			}
+
+			for i, v := range vs {
+				vs[i] = t.redact.replace(v)
+			}
+		}
+
+		u2.RawQuery = q.Encode()
+	}
+
+	return u2.Redacted()
+}
+
+// headers returns h as a group value with sensitive headers redacted.
+func (t *Transport) headers(h http.Header) slog.Value {
+	attrs := make([]slog.Attr, 0, len(h))
+
+	for k, vs := range h {
+		v := strings.Join(vs, ", ")
+
+		if isSensitiveHeader(k) || t.redact.matchKey(k) {
+			if t.redact.policy == RedactDrop {
+				continue
+			}
+
+			v = t.redact.replace(v)
+		}
+
+		attrs = append(attrs, slog.String(k, v))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// isSensitiveHeader reports whether the header always holds credentials.
+func isSensitiveHeader(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+
+	for _, s := range sensitiveHeaders {
+		if key == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// peekBody reads up to n bytes of body for logging and returns them as a string
+// together with a reader replaying the whole body.
+func peekBody(body io.ReadCloser, n int) (string, io.ReadCloser, error) {
+	buf := make([]byte, n+1)
+
+	read, err := io.ReadFull(body, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	buf = buf[:read]
+
+	rc := readCloser{Reader: io.MultiReader(bytes.NewReader(buf), body), Closer: body}
+	if read > n {
+		return truncateBytes(string(buf), n), rc, err
+	}
+
+	return string(buf), rc, err
+}
+
+// dumpBody captures the first bytes of a response body while it is read
+// and logs them once the body has been read to the end or is closed.
+type dumpBody struct {
+	io.ReadCloser
+
+	max  int
+	buf  []byte
+	once sync.Once
+	log  func(body string)
+}
+
+// Read implements io.Reader.
+func (b *dumpBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	// one byte more than max tells whether the body was truncated
+	if keep := b.max + 1 - len(b.buf); keep > 0 {
+		b.buf = append(b.buf, p[:min(n, keep)]...)
+	}
+
+	if err == io.EOF {
+		b.done()
+	}
+
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *dumpBody) Close() error {
+	b.done()
+
+	return b.ReadCloser.Close()
+}
+
+// done logs the captured body, once.
+func (b *dumpBody) done() {
+	b.once.Do(func() {
+		b.log(truncateBytes(string(b.buf), b.max))
+	})
+}
+
+// readCloser combines a Reader with the Closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// retryKey is the context key for the retry count stored by WithRetryCount.
+type retryKey struct{}
+
+// WithRetryCount returns a copy of ctx recording that a request is the n-th retry.
+// Retry loops set it on each attempt so that Transport logs the retry count.
+func WithRetryCount(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, retryKey{}, n)
+}
+
+// RetryCount returns the retry count stored in ctx by WithRetryCount, or zero.
+func RetryCount(ctx context.Context) int {
+	n, _ := ctx.Value(retryKey{}).(int)
+	return n
+}